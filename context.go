@@ -0,0 +1,32 @@
+package vibe
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/vibe-go/vibe/middleware"
+)
+
+// OutboundContext returns the context to pass to downstream HTTP and
+// database clients while handling r. It is r's context, so it carries the
+// request's deadline and is canceled with it, and it carries the request ID
+// for correlation: the one set by the RequestID middleware, or else the
+// incoming X-Request-ID header. Read the ID back with
+// middleware.RequestIDFrom.
+//
+// Example:
+//
+//	ctx := vibe.OutboundContext(r)
+//	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, inventoryURL, nil)
+//	req.Header.Set(middleware.RequestIDHeader, middleware.RequestIDFrom(ctx))
+//	resp, err := client.Do(req)
+func OutboundContext(r *http.Request) context.Context {
+	ctx := r.Context()
+	if middleware.RequestIDFrom(ctx) != "" {
+		return ctx
+	}
+	if id := r.Header.Get(middleware.RequestIDHeader); id != "" {
+		ctx = middleware.ContextWithRequestID(ctx, id)
+	}
+	return ctx
+}
@@ -0,0 +1,85 @@
+package cookie_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/vibe-go/vibe/cookie"
+)
+
+// roundTrip sets a cookie on a recorder and returns a request carrying it.
+func roundTrip(t *testing.T, w *httptest.ResponseRecorder) *http.Request {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, c := range w.Result().Cookies() {
+		req.AddCookie(c)
+	}
+	return req
+}
+
+func TestSignedCookie(t *testing.T) {
+	signer := cookie.Signed([]byte("secret"))
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		signer.Set(w, "theme", "dark mode")
+
+		value, err := signer.Get(roundTrip(t, w), "theme")
+		if err != nil {
+			t.Fatalf("Get returned unexpected error: %v", err)
+		}
+		if value != "dark mode" {
+			t.Errorf("Expected value 'dark mode', got '%s'", value)
+		}
+	})
+
+	t.Run("Options", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		signer.Set(w, "theme", "dark",
+			cookie.WithSecure(true),
+			cookie.WithMaxAge(60),
+			cookie.WithSameSite(http.SameSiteStrictMode),
+		)
+
+		c := w.Result().Cookies()[0]
+		if !c.HttpOnly || !c.Secure || c.MaxAge != 60 || c.SameSite != http.SameSiteStrictMode {
+			t.Errorf("Unexpected cookie attributes: %+v", c)
+		}
+	})
+
+	t.Run("Tampered", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		signer.Set(w, "role", "user")
+
+		c := w.Result().Cookies()[0]
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.AddCookie(&http.Cookie{Name: "role", Value: "YWRtaW4" + c.Value[len("dXNlcg"):]})
+
+		_, err := signer.Get(req, "role")
+		if !errors.Is(err, cookie.ErrInvalidSignature) {
+			t.Errorf("Expected ErrInvalidSignature, got %v", err)
+		}
+	})
+
+	t.Run("WrongSecret", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		cookie.Signed([]byte("other")).Set(w, "role", "user")
+
+		_, err := signer.Get(roundTrip(t, w), "role")
+		if !errors.Is(err, cookie.ErrInvalidSignature) {
+			t.Errorf("Expected ErrInvalidSignature, got %v", err)
+		}
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+		_, err := signer.Get(req, "missing")
+		if !errors.Is(err, http.ErrNoCookie) {
+			t.Errorf("Expected http.ErrNoCookie, got %v", err)
+		}
+	})
+}
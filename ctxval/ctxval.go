@@ -0,0 +1,44 @@
+// Package ctxval provides typed helpers for storing request-scoped values in
+// the request context, so middleware and handlers can share data without
+// managing context keys by hand.
+package ctxval
+
+import (
+	"context"
+	"net/http"
+)
+
+// key is the context key for values of type T.
+// Using a distinct type per T prevents collisions between packages.
+type key[T any] struct{}
+
+// Set returns a shallow copy of r whose context carries val.
+// A later Set with the same type T replaces the earlier value.
+//
+// Example:
+//
+//	r = ctxval.Set(r, currentUser)
+func Set[T any](r *http.Request, val T) *http.Request {
+	return r.WithContext(WithValue(r.Context(), val))
+}
+
+// Get returns the value of type T stored in the request context, and whether
+// it was present.
+//
+// Example:
+//
+//	user, ok := ctxval.Get[*User](r)
+func Get[T any](r *http.Request) (T, bool) {
+	return Value[T](r.Context())
+}
+
+// WithValue returns a copy of ctx carrying val.
+func WithValue[T any](ctx context.Context, val T) context.Context {
+	return context.WithValue(ctx, key[T]{}, val)
+}
+
+// Value returns the value of type T stored in ctx, and whether it was present.
+func Value[T any](ctx context.Context) (T, bool) {
+	val, ok := ctx.Value(key[T]{}).(T)
+	return val, ok
+}
@@ -0,0 +1,60 @@
+package ctxval_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/vibe-go/vibe/ctxval"
+	"github.com/vibe-go/vibe/httpx"
+)
+
+type user struct {
+	Name string
+}
+
+type tenant string
+
+func TestSetGet(t *testing.T) {
+	// Middleware stores values of two distinct types
+	mw := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r = ctxval.Set(r, &user{Name: "alice"})
+			r = ctxval.Set(r, tenant("acme"))
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	var gotUser *user
+	var gotTenant tenant
+	var userOK, tenantOK bool
+
+	handler := httpx.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		gotUser, userOK = ctxval.Get[*user](r)
+		gotTenant, tenantOK = ctxval.Get[tenant](r)
+		w.WriteHeader(http.StatusOK)
+		return nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+
+	mw(handler).ServeHTTP(w, req)
+
+	if !userOK || gotUser == nil || gotUser.Name != "alice" {
+		t.Errorf("Expected user 'alice', got %+v (ok=%v)", gotUser, userOK)
+	}
+
+	if !tenantOK || gotTenant != "acme" {
+		t.Errorf("Expected tenant 'acme', got '%s' (ok=%v)", gotTenant, tenantOK)
+	}
+}
+
+func TestGetMissing(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	val, ok := ctxval.Get[string](req)
+	if ok {
+		t.Errorf("Expected no value, got '%s'", val)
+	}
+}
@@ -0,0 +1,104 @@
+package vibe
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/vibe-go/vibe/httpx"
+)
+
+// JSONHandler adapts a typed function into an httpx.HandlerFunc.
+// The request body is decoded as JSON into In, fn is called with the request
+// context, and its result is encoded as JSON with status 200 OK.
+//
+// A body that cannot be decoded is rejected with 400 Bad Request and a
+// message naming the offending offset or field, before fn is called. Errors
+// returned by fn are reported with the status of an *httpx.StatusError, or
+// 500 Internal Server Error otherwise.
+//
+// Example:
+//
+//	router.Post("/greet", vibe.JSONHandler(func(ctx context.Context, in GreetRequest) (GreetResponse, error) {
+//	    return GreetResponse{Message: "Hello, " + in.Name}, nil
+//	}))
+func JSONHandler[In, Out any](fn func(ctx context.Context, in In) (Out, error)) httpx.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var in In
+		if err := httpx.DecodeJSON(r, &in); err != nil {
+			return decodeError(w, err)
+		}
+
+		out, err := fn(r.Context(), in)
+		if err != nil {
+			return err
+		}
+
+		return httpx.JSON(w, out, http.StatusOK)
+	}
+}
+
+// BindHandler adapts a typed function into an httpx.HandlerFunc like
+// JSONHandler, but also binds path and query parameters into In using its
+// `path` and `query` struct tags (see httpx.BindParams). The JSON body, if
+// any, is decoded first, so parameters take precedence over body fields.
+//
+// Example:
+//
+//	type GetUser struct {
+//	    ID      int  `path:"id"`
+//	    Verbose bool `query:"verbose"`
+//	}
+//
+//	router.Get("/users/{id}", vibe.BindHandler(func(ctx context.Context, in GetUser) (User, error) {
+//	    return users.Find(ctx, in.ID, in.Verbose)
+//	}))
+func BindHandler[In, Out any](fn func(ctx context.Context, in In) (Out, error)) httpx.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		var in In
+		if r.ContentLength != 0 {
+			if err := httpx.DecodeJSON(r, &in); err != nil {
+				return decodeError(w, err)
+			}
+		}
+
+		if err := httpx.BindParams(r, &in); err != nil {
+			return httpx.BadRequest(w, err)
+		}
+
+		out, err := fn(r.Context(), in)
+		if err != nil {
+			return err
+		}
+
+		return httpx.JSON(w, out, http.StatusOK)
+	}
+}
+
+// decodeError reports a failure to decode the request body of a typed
+// handler. Errors carrying a status, such as an oversized body, keep it;
+// everything else is a client error and is reported as 400 Bad Request with
+// a message describing what was wrong with the JSON.
+func decodeError(w http.ResponseWriter, err error) error {
+	var statusErr *httpx.StatusError
+	if errors.As(err, &statusErr) {
+		return httpx.Error(w, statusErr, statusErr.Status)
+	}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		err = fmt.Errorf("malformed JSON at offset %d: %w", syntaxErr.Offset, syntaxErr)
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		err = fmt.Errorf("invalid value for field %q: expected %s", typeErr.Field, typeErr.Type)
+	case errors.Is(err, io.EOF):
+		err = errors.New("request body is empty")
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		err = errors.New("malformed JSON: unexpected end of input")
+	}
+	return httpx.BadRequest(w, err)
+}
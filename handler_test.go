@@ -0,0 +1,159 @@
+package vibe_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/vibe-go/vibe"
+	"github.com/vibe-go/vibe/httpx"
+)
+
+type greetRequest struct {
+	Name string `json:"name"`
+}
+
+type greetResponse struct {
+	Message string `json:"message"`
+}
+
+func greet(_ context.Context, in greetRequest) (greetResponse, error) {
+	if in.Name == "" {
+		return greetResponse{}, httpx.NewStatusError(http.StatusUnprocessableEntity, errors.New("name is required"))
+	}
+	return greetResponse{Message: "Hello, " + in.Name}, nil
+}
+
+func TestJSONHandler(t *testing.T) {
+	router := vibe.New()
+	router.Post("/greet", vibe.JSONHandler(greet))
+
+	tests := []struct {
+		name     string
+		body     string
+		status   int
+		contains string
+	}{
+		{"Success", `{"name":"Vibe"}`, http.StatusOK, `"message":"Hello, Vibe"`},
+		{"StatusError", `{}`, http.StatusUnprocessableEntity, "name is required"},
+		{"TruncatedJSON", `{"name":`, http.StatusBadRequest, "unexpected end of input"},
+		{"MalformedJSON", `{"name" "Vibe"}`, http.StatusBadRequest, "malformed JSON at offset"},
+		{"WrongType", `{"name":42}`, http.StatusBadRequest, `invalid value for field \"name\": expected string`},
+		{"EmptyBody", ``, http.StatusBadRequest, "request body is empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/greet", strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Errorf("Expected status code %d, got %d", tt.status, w.Code)
+			}
+
+			if !strings.Contains(w.Body.String(), tt.contains) {
+				t.Errorf("Expected body to contain %s, got %s", tt.contains, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestJSONHandlerErrors(t *testing.T) {
+	router := vibe.New()
+	router.Post("/fail", vibe.JSONHandler(func(_ context.Context, _ greetRequest) (greetResponse, error) {
+		return greetResponse{}, errors.New("database unavailable")
+	}))
+
+	// Test case: a malformed body is a client error, even if fn would fail
+	t.Run("DecodeFailure", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/fail", strings.NewReader(`{"name":`))
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
+		}
+
+		var body map[string]string
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body["error"] == "" {
+			t.Errorf("Expected structured JSON error, got %s", w.Body.String())
+		}
+	})
+
+	// Test case: handler-logic errors keep their 500 status
+	t.Run("HandlerFailure", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/fail", strings.NewReader(`{"name":"Vibe"}`))
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("Expected status code %d, got %d", http.StatusInternalServerError, w.Code)
+		}
+	})
+}
+
+type getUserRequest struct {
+	ID      int    `path:"id"`
+	Verbose bool   `query:"verbose"`
+	Note    string `json:"note"`
+}
+
+func TestBindHandler(t *testing.T) {
+	router := vibe.New()
+	router.Get("/users/{id}", vibe.BindHandler(func(_ context.Context, in getUserRequest) (getUserRequest, error) {
+		return in, nil
+	}))
+	router.Put("/users/{id}", vibe.BindHandler(func(_ context.Context, in getUserRequest) (getUserRequest, error) {
+		return in, nil
+	}))
+
+	t.Run("PathAndQuery", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/users/7?verbose=true", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+		}
+
+		var out getUserRequest
+		json.Unmarshal(w.Body.Bytes(), &out)
+		// ID and Verbose have no json tags, so they are encoded by field name
+		if out.ID != 7 || !out.Verbose {
+			t.Errorf("Expected id 7 and verbose true, got %+v", out)
+		}
+	})
+
+	t.Run("WithBody", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPut, "/users/7", strings.NewReader(`{"note":"hi"}`))
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		var out getUserRequest
+		json.Unmarshal(w.Body.Bytes(), &out)
+		if out.ID != 7 || out.Note != "hi" {
+			t.Errorf("Expected id 7 and note 'hi', got %+v", out)
+		}
+	})
+
+	t.Run("InvalidPath", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/users/abc", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
+		}
+	})
+}
@@ -0,0 +1,106 @@
+package httpx
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+)
+
+// BindParams populates the fields of the struct pointed to by v from the
+// request's path and query parameters. Fields are matched by their `path`
+// and `query` struct tags:
+//
+//	type GetUser struct {
+//	    ID      int  `path:"id"`
+//	    Verbose bool `query:"verbose"`
+//	}
+//
+// Supported field types are strings, booleans, integers, floats and, for
+// query parameters, slices of those. Parameters that are absent leave the
+// field unchanged; values that cannot be parsed produce an error.
+func BindParams(r *http.Request, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("bind target must be a pointer to a struct")
+	}
+	rv = rv.Elem()
+	rt := rv.Type()
+
+	query := r.URL.Query()
+	for i := range rt.NumField() {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		if name, ok := field.Tag.Lookup("path"); ok {
+			if value := r.PathValue(name); value != "" {
+				if err := setField(rv.Field(i), []string{value}); err != nil {
+					return fmt.Errorf("invalid path parameter %q: %w", name, err)
+				}
+			}
+		}
+
+		if name, ok := field.Tag.Lookup("query"); ok {
+			if values, ok := query[name]; ok && len(values) > 0 {
+				if err := setField(rv.Field(i), values); err != nil {
+					return fmt.Errorf("invalid query parameter %q: %w", name, err)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// setField assigns values to field, parsing them according to its type.
+func setField(field reflect.Value, values []string) error {
+	if field.Kind() == reflect.Slice {
+		slice := reflect.MakeSlice(field.Type(), len(values), len(values))
+		for i, value := range values {
+			if err := setValue(slice.Index(i), value); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+		return nil
+	}
+	return setValue(field, values[0])
+}
+
+// setValue parses value into the scalar field.
+func setValue(field reflect.Value, value string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
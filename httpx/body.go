@@ -0,0 +1,56 @@
+package httpx
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// defaultRawBodyLimit is the body size RawBody accepts without WithMaxBytes.
+const defaultRawBodyLimit = 10 << 20
+
+// RawBody reads and returns the request body, leaving r.Body readable again
+// from the start, so that a handler can inspect the body and still forward
+// it, e.g. as the body of an outgoing request. r.GetBody is set as well.
+// Bodies over the limit set with WithMaxBytes, 10 MB by default, are
+// rejected with a *StatusError with 413 Request Entity Too Large.
+//
+// Example:
+//
+//	body, err := httpx.RawBody(r)
+//	if err != nil {
+//		return err
+//	}
+//	if !json.Valid(body) {
+//		return httpx.BadRequest(w, errors.New("invalid JSON"))
+//	}
+//	proxy.ServeHTTP(w, r)
+func RawBody(r *http.Request, options ...DecodeOption) ([]byte, error) {
+	cfg := &decodeConfig{maxBytes: defaultRawBodyLimit}
+	for _, option := range options {
+		option(cfg)
+	}
+
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, nil
+	}
+	if r.ContentLength > cfg.maxBytes {
+		return nil, tooLarge(cfg.maxBytes)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, cfg.maxBytes+1))
+	r.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	if int64(len(body)) > cfg.maxBytes {
+		return nil, tooLarge(cfg.maxBytes)
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	r.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	return body, nil
+}
@@ -0,0 +1,25 @@
+package httpx
+
+import (
+	"net/http"
+	"time"
+)
+
+// TimeLeft returns how much time remains before the request's context
+// deadline, such as the one set by the timeout middleware, and whether the
+// request has a deadline at all. Handlers can use it to skip expensive work
+// that could not finish in time. The duration is negative once the deadline
+// has passed.
+//
+// Example:
+//
+//	if left, ok := httpx.TimeLeft(r); ok && left < 200*time.Millisecond {
+//		return httpx.JSON(w, cachedSummary, http.StatusOK)
+//	}
+func TimeLeft(r *http.Request) (time.Duration, bool) {
+	deadline, ok := r.Context().Deadline()
+	if !ok {
+		return 0, false
+	}
+	return time.Until(deadline), true
+}
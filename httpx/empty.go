@@ -0,0 +1,82 @@
+package httpx
+
+import (
+	"context"
+	"reflect"
+)
+
+// emptyCollectionsKey is the context key for the flag set by
+// ContextWithEmptyCollections.
+type emptyCollectionsKey struct{}
+
+// ContextWithEmptyCollections returns a copy of ctx in which JSON and
+// JSONWithHeaders render nil slices and maps at the top level of the
+// response as [] and {} instead of null, as if every response were passed
+// through EmptyCollections. middleware.EmptyCollections sets it for a router
+// or group.
+func ContextWithEmptyCollections(ctx context.Context) context.Context {
+	return context.WithValue(ctx, emptyCollectionsKey{}, true)
+}
+
+// EmptyCollectionsFromContext reports whether ctx was returned by
+// ContextWithEmptyCollections.
+func EmptyCollectionsFromContext(ctx context.Context) bool {
+	on, _ := ctx.Value(emptyCollectionsKey{}).(bool)
+	return on
+}
+
+// EmptyCollections returns data with nil slices and maps replaced by empty
+// ones, so that they encode as [] and {} rather than null. It applies to data
+// itself and, for a struct or pointer to a struct, to its exported fields;
+// nested values are left as they are. data is not modified; a changed struct
+// is returned as a copy.
+//
+// Example:
+//
+//	return httpx.JSON(w, httpx.EmptyCollections(page), http.StatusOK)
+func EmptyCollections(data interface{}) interface{} {
+	v := reflect.ValueOf(data)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		if v.IsNil() {
+			return emptyValue(v.Type()).Interface()
+		}
+	case reflect.Struct:
+		if s, ok := emptyFields(v); ok {
+			return s.Interface()
+		}
+	case reflect.Pointer:
+		if !v.IsNil() && v.Elem().Kind() == reflect.Struct {
+			if s, ok := emptyFields(v.Elem()); ok {
+				return s.Addr().Interface()
+			}
+		}
+	}
+	return data
+}
+
+// emptyFields returns an addressable copy of the struct v with nil slice and
+// map fields replaced by empty ones, and whether any field was replaced.
+func emptyFields(v reflect.Value) (reflect.Value, bool) {
+	var s reflect.Value
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Field(i)
+		if !v.Type().Field(i).IsExported() || (f.Kind() != reflect.Slice && f.Kind() != reflect.Map) || !f.IsNil() {
+			continue
+		}
+		if !s.IsValid() {
+			s = reflect.New(v.Type()).Elem()
+			s.Set(v)
+		}
+		s.Field(i).Set(emptyValue(f.Type()))
+	}
+	return s, s.IsValid()
+}
+
+// emptyValue returns an empty, non-nil slice or map of type t.
+func emptyValue(t reflect.Type) reflect.Value {
+	if t.Kind() == reflect.Map {
+		return reflect.MakeMap(t)
+	}
+	return reflect.MakeSlice(t, 0, 0)
+}
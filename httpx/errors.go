@@ -1,27 +1,86 @@
 package httpx
 
 import (
+	"context"
+	"encoding/xml"
 	"errors"
 	"fmt"
+	"log"
 	"net/http"
+	"strings"
+	"sync"
 )
 
 // ErrorResponder is an interface for responding with errors in different formats.
 type ErrorResponder interface {
-	// Error writes an error response in the appropriate format.
+	// Error writes an error response in the appropriate format. r is the
+	// request being answered; it is nil when Error is called on a writer
+	// that was not passed through a HandlerFunc.
+	Error(w http.ResponseWriter, r *http.Request, err error, status int) error
+}
+
+// LegacyErrorResponder is the ErrorResponder signature that predates passing
+// the request. Wrap implementations with AdaptResponder to keep using them.
+type LegacyErrorResponder interface {
 	Error(w http.ResponseWriter, err error, status int) error
 }
 
+// legacyResponder adapts a LegacyErrorResponder to ErrorResponder.
+type legacyResponder struct {
+	LegacyErrorResponder
+}
+
+// Error writes the error with the legacy responder, ignoring the request.
+func (l legacyResponder) Error(w http.ResponseWriter, _ *http.Request, err error, status int) error {
+	return l.LegacyErrorResponder.Error(w, err, status)
+}
+
+// AdaptResponder returns an ErrorResponder that delegates to a responder
+// written against the legacy Error(w, err, status) signature.
+//
+// Example:
+//
+//	httpx.SetDefaultResponder(httpx.AdaptResponder(myOldResponder{}))
+func AdaptResponder(legacy LegacyErrorResponder) ErrorResponder {
+	return legacyResponder{legacy}
+}
+
 // JSONErrorResponder implements ErrorResponder for JSON responses.
 type JSONErrorResponder struct{}
 
-// Error writes a JSON error response.
-func (r JSONErrorResponder) Error(w http.ResponseWriter, err error, status int) error {
-	message := "unknown error"
+// Error writes a JSON error response. If err carries the ID of the request,
+// i.e. it or an error it wraps has a RequestID() string method returning a
+// non-empty ID, the ID is added as a "request_id" field.
+func (JSONErrorResponder) Error(w http.ResponseWriter, r *http.Request, err error, status int) error {
+	message := Message(r, MessageUnknownError)
+	if err != nil {
+		message = err.Error()
+	}
+	body := map[string]string{"error": message}
+	var withID interface{ RequestID() string }
+	if errors.As(err, &withID) && withID.RequestID() != "" {
+		body["request_id"] = withID.RequestID()
+	}
+	return JSON(w, body, status)
+}
+
+// XMLErrorResponder implements ErrorResponder for XML responses.
+type XMLErrorResponder struct{}
+
+// xmlError is the XML body written by XMLErrorResponder.
+type xmlError struct {
+	XMLName xml.Name `xml:"error"`
+	Message string   `xml:",chardata"`
+}
+
+// Error writes an XML error response.
+func (XMLErrorResponder) Error(w http.ResponseWriter, r *http.Request, err error, status int) error {
+	message := Message(r, MessageUnknownError)
 	if err != nil {
 		message = err.Error()
 	}
-	return JSON(w, map[string]string{"error": message}, status)
+
+	return XML(w, xmlError{Message: message}, status)
 }
 
 // defaultResponder is the default error responder (JSON).
@@ -37,15 +96,223 @@ func SetDefaultResponder(responder ErrorResponder) {
 	defaultResponder = responder
 }
 
-// Error responds with an error message in the default format and the given status code.
+// errorLogger logs errors that could not be sent to the client.
+var errorLogger = log.Default()
+
+// SetErrorLogger sets the logger for errors that Error cannot send because
+// the response has already started. A nil logger restores the standard
+// logger.
+//
+// Example:
+//
+//	httpx.SetErrorLogger(log.New(os.Stderr, "[httpx] ", log.LstdFlags))
+func SetErrorLogger(logger *log.Logger) {
+	if logger == nil {
+		logger = log.Default()
+	}
+	errorLogger = logger
+}
+
+var (
+	registryMu sync.RWMutex
+	// registered maps media types to their responders; registeredOrder
+	// keeps registration order so that ties resolve deterministically.
+	registered      = map[string]ErrorResponder{}
+	registeredOrder []string
+)
+
+// RegisterResponder registers responder for errors sent to clients that
+// accept mediaType. Error picks among the registered responders using the
+// request's Accept header and falls back to the default responder when none
+// matches or the client accepts anything. Registering a nil responder
+// removes the registration for mediaType.
+//
+// Example:
+//
+//	httpx.RegisterResponder("application/xml", httpx.XMLErrorResponder{})
+func RegisterResponder(mediaType string, responder ErrorResponder) {
+	mediaType = strings.ToLower(mediaType)
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, ok := registered[mediaType]; ok {
+		if responder != nil {
+			registered[mediaType] = responder
+			return
+		}
+		delete(registered, mediaType)
+		for i, t := range registeredOrder {
+			if t == mediaType {
+				registeredOrder = append(registeredOrder[:i], registeredOrder[i+1:]...)
+				break
+			}
+		}
+		return
+	}
+
+	if responder != nil {
+		registered[mediaType] = responder
+		registeredOrder = append(registeredOrder, mediaType)
+	}
+}
+
+// negotiateResponder returns the registered responder preferred by the
+// request's Accept header, or nil if the default responder should be used.
+func negotiateResponder(r *http.Request) ErrorResponder {
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		return nil
+	}
+
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	if len(registeredOrder) == 0 {
+		return nil
+	}
+
+	for _, mr := range parseAccept(accept) {
+		if mr.mediaType == "*/*" {
+			return nil
+		}
+		for _, t := range registeredOrder {
+			if mr.matches(t) {
+				return registered[t]
+			}
+		}
+	}
+
+	return nil
+}
+
+// responderKey is the context key for a request-scoped ErrorResponder.
+type responderKey struct{}
+
+// ContextWithResponder returns a copy of ctx carrying responder. Handlers
+// served with that context write their errors with responder instead of
+// the default responder.
+func ContextWithResponder(ctx context.Context, responder ErrorResponder) context.Context {
+	return context.WithValue(ctx, responderKey{}, responder)
+}
+
+// ResponderFromContext returns the ErrorResponder stored in ctx, and whether
+// one was present.
+func ResponderFromContext(ctx context.Context) (ErrorResponder, bool) {
+	responder, ok := ctx.Value(responderKey{}).(ErrorResponder)
+	return responder, ok
+}
+
+// defaultStatusKey is the context key for the status of untyped errors.
+type defaultStatusKey struct{}
+
+// ContextWithDefaultStatus returns a copy of ctx carrying status. Errors
+// without a status of their own, i.e. not wrapping a *StatusError, returned
+// by handlers served with that context are reported with status instead of
+// 500 Internal Server Error.
+func ContextWithDefaultStatus(ctx context.Context, status int) context.Context {
+	return context.WithValue(ctx, defaultStatusKey{}, status)
+}
+
+// DefaultStatusFromContext returns the status stored in ctx by
+// ContextWithDefaultStatus, and whether one was present.
+func DefaultStatusFromContext(ctx context.Context) (int, bool) {
+	status, ok := ctx.Value(defaultStatusKey{}).(int)
+	return status, ok
+}
+
+// responderFor returns the ErrorResponder for the response w, along with
+// the request recorded by HandlerFunc, if any. The responder is the one
+// attached to w by HandlerFunc, then a registered responder negotiated from
+// the request's Accept header, then the default responder.
+func responderFor(w http.ResponseWriter) (ErrorResponder, *http.Request) {
+	var responder ErrorResponder
+	var r *http.Request
+	for w != nil {
+		if rw, ok := w.(*ResponseWriter); ok {
+			if responder == nil {
+				responder = rw.responder
+			}
+			if r == nil {
+				r = rw.request
+			}
+		}
+		uw, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			break
+		}
+		w = uw.Unwrap()
+	}
+	if responder != nil {
+		return responder, r
+	}
+	if r != nil {
+		if responder := negotiateResponder(r); responder != nil {
+			return responder, r
+		}
+	}
+	return DefaultResponder(), r
+}
+
+// Error responds with an error message in the request's error format and the
+// given status code. The format is the one set with ContextWithResponder for
+// the request, then one registered with RegisterResponder that matches the
+// request's Accept header, or the default format otherwise.
+// If the response has already started, the error cannot be sent to the client;
+// it is logged to the logger set with SetErrorLogger instead and nothing is
+// written.
 func Error(w http.ResponseWriter, err error, status int) error {
-	return DefaultResponder().Error(w, err, status)
+	if Written(w) {
+		errorLogger.Printf("httpx: response already started, dropping error response (%d): %v", status, err)
+		return nil
+	}
+	responder, r := responderFor(w)
+	return responder.Error(w, r, err, status)
+}
+
+// ErrAbort signals that a HandlerFunc ended the request on purpose, e.g. a
+// middleware that has already written a 401 response and does not call the
+// next handler. Returning it (or an error wrapping it) stops processing
+// without an error response being written or logged.
+//
+// Example:
+//
+//	if !authorized(r) {
+//		httpx.Error(w, errors.New("unauthorized"), http.StatusUnauthorized)
+//		return httpx.ErrAbort
+//	}
+var ErrAbort = errors.New("httpx: request aborted")
+
+// StatusError is an error that carries the HTTP status code it should be
+// reported with. Returning one from a HandlerFunc responds with that status
+// instead of 500 Internal Server Error.
+type StatusError struct {
+	Status int
+	Err    error
+}
+
+// NewStatusError wraps err with the given HTTP status code.
+func NewStatusError(status int, err error) *StatusError {
+	return &StatusError{Status: status, Err: err}
+}
+
+// Error returns the message of the wrapped error.
+func (e *StatusError) Error() string {
+	if e.Err == nil {
+		return http.StatusText(e.Status)
+	}
+	return e.Err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (e *StatusError) Unwrap() error {
+	return e.Err
 }
 
 // NotFound is a convenience function for 404 responses.
 func NotFound(w http.ResponseWriter, err error) error {
 	if err == nil {
-		err = errors.New("resource not found")
+		err = errors.New(Message(requestFor(w), MessageNotFound))
 	}
 	return Error(w, err, http.StatusNotFound)
 }
@@ -57,10 +324,17 @@ func BadRequest(w http.ResponseWriter, err error) error {
 
 // InternalError is a convenience function for 500 responses.
 func InternalError(w http.ResponseWriter, err error) error {
+	message := Message(requestFor(w), MessageInternalError)
 	if err == nil {
-		err = errors.New("internal server error")
+		err = errors.New(message)
 	} else {
-		err = fmt.Errorf("internal server error: %w", err)
+		err = fmt.Errorf("%s: %w", message, err)
 	}
 	return Error(w, err, http.StatusInternalServerError)
 }
+
+// ValidationErrors responds with a 422 status and a map of field names to
+// validation messages, so that all field errors can be reported at once.
+func ValidationErrors(w http.ResponseWriter, errs map[string]string) error {
+	return JSON(w, map[string]map[string]string{"errors": errs}, http.StatusUnprocessableEntity)
+}
@@ -1,14 +1,95 @@
 package httpx
 
-import "net/http"
+import (
+	"errors"
+	"net/http"
+)
 
 type HandlerFunc func(w http.ResponseWriter, r *http.Request) error
 
 func (h HandlerFunc) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rw := NewResponseWriter(w)
+	rw.request = r
+	if responder, ok := ResponderFromContext(r.Context()); ok {
+		rw.responder = responder
+	}
+	w = rw
 	if err := h(w, r); err != nil {
-		err = InternalError(w, err)
+		if errors.Is(err, ErrAbort) {
+			return
+		}
+		// Hand the error to an enclosing ErrorMiddleware instead of
+		// responding, so that it can inspect it first.
+		if outer := errorCollector(w); outer != nil {
+			outer.pending = err
+			return
+		}
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) {
+			err = Error(w, statusErr, statusErr.Status)
+		} else if status, ok := DefaultStatusFromContext(r.Context()); ok && status != http.StatusInternalServerError {
+			err = Error(w, err, status)
+		} else {
+			err = InternalError(w, err)
+		}
 		if err != nil {
 			panic(err)
 		}
 	}
 }
+
+// ErrorMiddleware is middleware that sees the error returned by the handler
+// it wraps, e.g. to log or translate it, before it is turned into an error
+// response. Use AdaptErrorMiddleware to add one to a standard middleware chain.
+type ErrorMiddleware func(next HandlerFunc) HandlerFunc
+
+// AdaptErrorMiddleware converts mw into a standard func(http.Handler)
+// http.Handler middleware. Errors returned by HandlerFuncs further down the
+// chain are passed back up to mw instead of being written as responses,
+// even across plain http.Handler middleware in between, as long as those
+// pass the ResponseWriter through or wrap it with an Unwrap method. The
+// error mw returns is then reported as usual, e.g. as a 500.
+//
+// Example:
+//
+//	logErrors := func(next httpx.HandlerFunc) httpx.HandlerFunc {
+//		return func(w http.ResponseWriter, r *http.Request) error {
+//			err := next(w, r)
+//			if err != nil {
+//				log.Printf("%s %s: %v", r.Method, r.URL.Path, err)
+//			}
+//			return err
+//		}
+//	}
+//	router.Use(httpx.AdaptErrorMiddleware(logErrors))
+func AdaptErrorMiddleware(mw ErrorMiddleware) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return mw(func(w http.ResponseWriter, r *http.Request) error {
+			rw := NewResponseWriter(w)
+			collecting, pending := rw.collecting, rw.pending
+			rw.collecting, rw.pending = true, nil
+			defer func() {
+				rw.collecting, rw.pending = collecting, pending
+			}()
+
+			next.ServeHTTP(rw, r)
+			return rw.pending
+		})
+	}
+}
+
+// errorCollector returns the ResponseWriter of the nearest enclosing
+// ErrorMiddleware waiting for errors, or nil if there is none.
+func errorCollector(w http.ResponseWriter) *ResponseWriter {
+	for w != nil {
+		if rw, ok := w.(*ResponseWriter); ok && rw.collecting {
+			return rw
+		}
+		uw, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return nil
+		}
+		w = uw.Unwrap()
+	}
+	return nil
+}
@@ -2,15 +2,24 @@ package httpx_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"encoding/xml"
 	"errors"
+	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/vibe-go/vibe"
 	"github.com/vibe-go/vibe/httpx"
+	"github.com/vibe-go/vibe/middleware"
 )
 
 type testStruct struct {
@@ -53,6 +62,38 @@ func TestJSON(t *testing.T) {
 	}
 }
 
+func TestJSONEncodeFailure(t *testing.T) {
+	// Test case: direct call leaves the response untouched
+	t.Run("Direct", func(t *testing.T) {
+		w := httptest.NewRecorder()
+
+		err := httpx.JSON(w, map[string]interface{}{"ch": make(chan int)}, http.StatusOK)
+		if err == nil {
+			t.Fatal("JSON() didn't return error for an un-encodable value")
+		}
+
+		if w.Body.Len() != 0 || w.Header().Get("Content-Type") != "" {
+			t.Errorf("Expected nothing to be written, got headers %v and body %s", w.Header(), w.Body.String())
+		}
+	})
+
+	// Test case: handler returning the error responds with 500
+	t.Run("Handler", func(t *testing.T) {
+		handler := httpx.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) error {
+			return httpx.JSON(w, make(chan int), http.StatusOK)
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("Expected status code %d, got %d", http.StatusInternalServerError, w.Code)
+		}
+	})
+}
+
 func TestError(t *testing.T) {
 	w := httptest.NewRecorder()
 	testErr := errors.New("Invalid request")
@@ -205,6 +246,31 @@ func TestDecode(t *testing.T) {
 		}
 	})
 
+	t.Run("JSONWithCharset", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(`{"name":"test"}`))
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+		var result testStruct
+		if err := httpx.DecodeJSON(req, &result); err != nil {
+			t.Errorf("JSONDecode() returned error for JSON with charset: %v", err)
+		}
+	})
+
+	t.Run("NonJSONContentType", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(`{"name":"test"}`))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var result testStruct
+		err := httpx.DecodeJSON(req, &result)
+		var statusErr *httpx.StatusError
+		if !errors.As(err, &statusErr) {
+			t.Fatalf("Expected *httpx.StatusError for a form Content-Type, got %v", err)
+		}
+		if statusErr.Status != http.StatusUnsupportedMediaType {
+			t.Errorf("Expected status %d, got %d", http.StatusUnsupportedMediaType, statusErr.Status)
+		}
+	})
+
 	t.Run("DecodeNilBody", func(t *testing.T) {
 		// Test with nil body
 		req := httptest.NewRequest(http.MethodPost, "/", nil)
@@ -216,3 +282,1601 @@ func TestDecode(t *testing.T) {
 		}
 	})
 }
+
+func TestDecodeContext(t *testing.T) {
+	t.Run("StalledUpload", func(t *testing.T) {
+		done := make(chan error, 1)
+		handler := httpx.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) error {
+			var result testStruct
+			done <- httpx.DecodeJSON(r, &result)
+			return nil
+		})
+		server := httptest.NewServer(middleware.WithTimeout(100 * time.Millisecond)(handler))
+		defer server.Close()
+
+		// Send the start of a body, then stall without closing it.
+		body, stall := io.Pipe()
+		defer stall.Close()
+		go func() {
+			stall.Write([]byte(`{"name":`))
+		}()
+		go func() {
+			resp, err := http.Post(server.URL, "application/json", body)
+			if err == nil {
+				resp.Body.Close()
+			}
+		}()
+
+		select {
+		case err := <-done:
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Expected DecodeJSON to return after the context ended")
+		}
+	})
+
+	t.Run("StalledBody", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		body, stall := io.Pipe()
+		defer stall.Close()
+		go func() {
+			stall.Write([]byte(`{"name":`))
+		}()
+		req := httptest.NewRequest(http.MethodPost, "/", body).WithContext(ctx)
+
+		var result testStruct
+		if err := httpx.DecodeJSON(req, &result); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+		}
+	})
+
+	t.Run("AlreadyCanceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"test"}`)).WithContext(ctx)
+
+		var result testStruct
+		if err := httpx.DecodeJSON(req, &result); !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	})
+
+	t.Run("Completes", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"test","value":1}`)).WithContext(ctx)
+
+		var result testStruct
+		if err := httpx.DecodeJSON(req, &result); err != nil {
+			t.Fatalf("DecodeJSON() returned error: %v", err)
+		}
+		if result.Name != "test" || result.Value != 1 {
+			t.Errorf("Expected decoded body, got %+v", result)
+		}
+	})
+}
+
+func TestDecodeStrict(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr bool
+	}{
+		{"TrailingWhitespace", "{\"name\":\"test\"}\n ", false},
+		{"TrailingValue", `{"name":"test"} {"value":2}`, true},
+		{"TrailingGarbage", `{"name":"test"}]`, true},
+		{"UnknownField", `{"name":"test","extra":true}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			var result testStruct
+			err := httpx.DecodeJSON(req, &result, httpx.WithStrict())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Expected error: %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	t.Run("NotStrict", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"test"} {"value":2}`))
+
+		var result testStruct
+		if err := httpx.DecodeJSON(req, &result); err != nil {
+			t.Errorf("Expected trailing data to be ignored without WithStrict, got %v", err)
+		}
+	})
+}
+
+func TestValidationErrors(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	err := httpx.ValidationErrors(w, map[string]string{
+		"email": "is required",
+		"name":  "is too short",
+	})
+	if err != nil {
+		t.Errorf("ValidationErrors() returned error: %v", err)
+	}
+
+	resp := w.Result()
+	body, _ := io.ReadAll(resp.Body)
+
+	// Check status code
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status code %d, got %d", http.StatusUnprocessableEntity, resp.StatusCode)
+	}
+
+	// Check body
+	var result map[string]map[string]string
+	if err := json.Unmarshal(body, &result); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if result["errors"]["email"] != "is required" {
+		t.Errorf("Expected email error 'is required', got '%s'", result["errors"]["email"])
+	}
+	if result["errors"]["name"] != "is too short" {
+		t.Errorf("Expected name error 'is too short', got '%s'", result["errors"]["name"])
+	}
+}
+
+func TestPaginated(t *testing.T) {
+	w := httptest.NewRecorder()
+	items := []string{"a", "b", "c"}
+
+	err := httpx.Paginated(w, http.StatusOK, items, 2, 20, 101)
+	if err != nil {
+		t.Errorf("Paginated() returned error: %v", err)
+	}
+
+	resp := w.Result()
+	body, _ := io.ReadAll(resp.Body)
+
+	// Check status code
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	// Check body
+	var result struct {
+		Data       []string `json:"data"`
+		Page       int      `json:"page"`
+		PerPage    int      `json:"per_page"`
+		Total      int      `json:"total"`
+		TotalPages int      `json:"total_pages"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if len(result.Data) != 3 {
+		t.Errorf("Expected 3 items, got %d", len(result.Data))
+	}
+	if result.Page != 2 || result.PerPage != 20 || result.Total != 101 {
+		t.Errorf("Unexpected pagination metadata: %+v", result)
+	}
+	if result.TotalPages != 6 {
+		t.Errorf("Expected total_pages 6, got %d", result.TotalPages)
+	}
+}
+
+func TestCursor(t *testing.T) {
+	t.Run("WithCursor", func(t *testing.T) {
+		w := httptest.NewRecorder()
+
+		err := httpx.Cursor(w, http.StatusOK, []int{1, 2}, "abc123")
+		if err != nil {
+			t.Errorf("Cursor() returned error: %v", err)
+		}
+
+		expected := `{"data":[1,2],"next_cursor":"abc123"}`
+		if strings.TrimSpace(w.Body.String()) != expected {
+			t.Errorf("Expected body %s, got %s", expected, w.Body.String())
+		}
+	})
+
+	t.Run("EmptyCursor", func(t *testing.T) {
+		w := httptest.NewRecorder()
+
+		err := httpx.Cursor(w, http.StatusOK, []int{3}, "")
+		if err != nil {
+			t.Errorf("Cursor() returned error: %v", err)
+		}
+
+		if strings.Contains(w.Body.String(), "next_cursor") {
+			t.Errorf("Expected next_cursor to be omitted, got %s", w.Body.String())
+		}
+	})
+}
+
+func TestPreferredLanguage(t *testing.T) {
+	supported := []string{"de", "en", "fr"}
+
+	tests := []struct {
+		name     string
+		header   string
+		expected string
+	}{
+		{"NoHeader", "", "de"},
+		{"ExactMatch", "fr", "fr"},
+		{"QualityOrdering", "en-US,en;q=0.9,fr;q=0.8", "en"},
+		{"HigherQualityLater", "fr;q=0.5,en;q=0.9", "en"},
+		{"UnsupportedFallsThrough", "ja,fr;q=0.7", "fr"},
+		{"Wildcard", "ja,*;q=0.1", "de"},
+		{"ZeroQualityExcluded", "fr;q=0,en;q=0.5", "en"},
+		{"NoMatchDefault", "ja,zh", "de"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set("Accept-Language", tt.header)
+			}
+
+			got := httpx.PreferredLanguage(req, supported)
+			if got != tt.expected {
+				t.Errorf("Expected language '%s', got '%s'", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestErrorAfterResponseStarted(t *testing.T) {
+	t.Run("Direct", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		w := httpx.NewResponseWriter(rec)
+
+		w.Write([]byte("partial"))
+
+		err := httpx.Error(w, errors.New("too late"), http.StatusInternalServerError)
+		if err != nil {
+			t.Errorf("Error() returned error: %v", err)
+		}
+
+		if rec.Code != http.StatusOK {
+			t.Errorf("Expected status code %d, got %d", http.StatusOK, rec.Code)
+		}
+		if rec.Body.String() != "partial" {
+			t.Errorf("Expected body 'partial', got '%s'", rec.Body.String())
+		}
+	})
+
+	t.Run("HandlerReturnsError", func(t *testing.T) {
+		handler := httpx.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) error {
+			w.WriteHeader(http.StatusAccepted)
+			w.Write([]byte("partial"))
+			return errors.New("too late")
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusAccepted {
+			t.Errorf("Expected status code %d, got %d", http.StatusAccepted, rec.Code)
+		}
+		if rec.Body.String() != "partial" {
+			t.Errorf("Expected body 'partial', got '%s'", rec.Body.String())
+		}
+	})
+}
+
+func TestErrorLogger(t *testing.T) {
+	var buf bytes.Buffer
+	httpx.SetErrorLogger(log.New(&buf, "", 0))
+	defer httpx.SetErrorLogger(nil)
+
+	w := httpx.NewResponseWriter(httptest.NewRecorder())
+	w.Write([]byte("partial"))
+	httpx.Error(w, errors.New("too late"), http.StatusInternalServerError)
+
+	if !strings.Contains(buf.String(), "too late") {
+		t.Errorf("Expected the dropped error to be logged, got '%s'", buf.String())
+	}
+}
+
+func TestStatusError(t *testing.T) {
+	handler := httpx.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) error {
+		return fmt.Errorf("lookup: %w", httpx.NewStatusError(http.StatusConflict, errors.New("already exists")))
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected status code %d, got %d", http.StatusConflict, w.Code)
+	}
+
+	expected := `{"error":"already exists"}`
+	if strings.TrimSpace(w.Body.String()) != expected {
+		t.Errorf("Expected body %s, got %s", expected, w.Body.String())
+	}
+}
+
+func TestBindParams(t *testing.T) {
+	type params struct {
+		ID      int      `path:"id"`
+		Verbose bool     `query:"verbose"`
+		Tags    []string `query:"tag"`
+		Limit   uint     `query:"limit"`
+		Name    string
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/users/42?verbose=true&tag=a&tag=b", nil)
+		req.SetPathValue("id", "42")
+
+		p := params{Limit: 10}
+		if err := httpx.BindParams(req, &p); err != nil {
+			t.Fatalf("BindParams() returned error: %v", err)
+		}
+
+		if p.ID != 42 || !p.Verbose || len(p.Tags) != 2 || p.Tags[1] != "b" {
+			t.Errorf("BindParams() didn't bind correctly, got %+v", p)
+		}
+
+		if p.Limit != 10 {
+			t.Errorf("Expected absent parameter to keep its value, got %d", p.Limit)
+		}
+	})
+
+	t.Run("InvalidValue", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/users/abc", nil)
+		req.SetPathValue("id", "abc")
+
+		var p params
+		if err := httpx.BindParams(req, &p); err == nil {
+			t.Error("BindParams() didn't return error for an invalid integer")
+		}
+	})
+
+	t.Run("NotAPointer", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+		if err := httpx.BindParams(req, params{}); err == nil {
+			t.Error("BindParams() didn't return error for a non-pointer target")
+		}
+	})
+}
+
+func TestIsJSON(t *testing.T) {
+	tests := []struct {
+		contentType string
+		expected    bool
+	}{
+		{"application/json", true},
+		{"application/json; charset=utf-8", true},
+		{"Application/JSON", true},
+		{"application/problem+json", true},
+		{"text/plain", false},
+		{"application/jsonx", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			req.Header.Set("Content-Type", tt.contentType)
+
+			if got := httpx.IsJSON(req); got != tt.expected {
+				t.Errorf("Expected IsJSON %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestResponseWriterSize(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w := httpx.NewResponseWriter(rec)
+
+	err := httpx.JSON(w, map[string]string{"message": "hello"}, http.StatusOK)
+	if err != nil {
+		t.Fatalf("JSON() returned error: %v", err)
+	}
+
+	if w.Size() != rec.Body.Len() {
+		t.Errorf("Expected size %d, got %d", rec.Body.Len(), w.Size())
+	}
+
+	if w.Status() != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, w.Status())
+	}
+}
+
+func TestResponseWriterInformational(t *testing.T) {
+	var startedAfterHints bool
+	server := httptest.NewServer(httpx.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) error {
+		w.Header().Set("Link", "</app.css>; rel=preload")
+		w.WriteHeader(http.StatusEarlyHints)
+		startedAfterHints = httpx.Written(w)
+		w.WriteHeader(http.StatusCreated)
+		return nil
+	}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if startedAfterHints {
+		t.Error("Expected a 1xx status not to start the response")
+	}
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("Expected status code %d, got %d", http.StatusCreated, resp.StatusCode)
+	}
+}
+
+func TestResponseWriterInterfaces(t *testing.T) {
+	t.Run("ReaderFrom", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		w := httpx.NewResponseWriter(rec)
+
+		n, err := io.Copy(w, strings.NewReader("streamed"))
+		if err != nil {
+			t.Fatalf("io.Copy() returned error: %v", err)
+		}
+		if n != 8 || w.Size() != 8 {
+			t.Errorf("Expected 8 bytes copied and recorded, got %d and %d", n, w.Size())
+		}
+		if rec.Body.String() != "streamed" {
+			t.Errorf("Expected body 'streamed', got '%s'", rec.Body.String())
+		}
+	})
+
+	t.Run("Hijacker", func(t *testing.T) {
+		server := httptest.NewServer(httpx.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) error {
+			conn, buf, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+			buf.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 8\r\nConnection: close\r\n\r\nhijacked")
+			return buf.Flush()
+		}))
+		defer server.Close()
+
+		resp, err := http.Get(server.URL)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		defer resp.Body.Close()
+
+		body, _ := io.ReadAll(resp.Body)
+		if string(body) != "hijacked" {
+			t.Errorf("Expected body 'hijacked', got '%s'", body)
+		}
+	})
+
+	t.Run("HijackUnsupported", func(t *testing.T) {
+		w := httpx.NewResponseWriter(httptest.NewRecorder())
+
+		if _, _, err := w.Hijack(); err == nil {
+			t.Error("Expected Hijack() to fail on a writer that does not support it")
+		}
+	})
+}
+
+func TestNegotiateContentType(t *testing.T) {
+	offers := []string{"application/json", "application/xml"}
+
+	tests := []struct {
+		name     string
+		header   string
+		expected string
+	}{
+		{"NoHeader", "", "application/json"},
+		{"ExactMatch", "application/xml", "application/xml"},
+		{"QualityOrdering", "application/json;q=0.5, application/xml", "application/xml"},
+		{"TypeWildcard", "text/html, application/*;q=0.8", "application/json"},
+		{"FullWildcard", "*/*", "application/json"},
+		{"ZeroQualityExcluded", "application/json;q=0, application/xml;q=0.1", "application/xml"},
+		{"NoMatch", "text/html", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set("Accept", tt.header)
+			}
+
+			got := httpx.NegotiateContentType(req, offers)
+			if got != tt.expected {
+				t.Errorf("Expected content type '%s', got '%s'", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRegisterResponder(t *testing.T) {
+	httpx.RegisterResponder("application/xml", httpx.XMLErrorResponder{})
+	defer httpx.RegisterResponder("application/xml", nil)
+
+	handler := httpx.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) error {
+		return httpx.NewStatusError(http.StatusNotFound, errors.New("no such item"))
+	})
+
+	t.Run("XMLAccepted", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept", "application/xml")
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status code %d, got %d", http.StatusNotFound, w.Code)
+		}
+
+		contentType := w.Header().Get("Content-Type")
+		if !strings.HasPrefix(contentType, "application/xml") {
+			t.Errorf("Expected Content-Type 'application/xml', got '%s'", contentType)
+		}
+
+		expected := "<error>no such item</error>"
+		if !strings.Contains(w.Body.String(), expected) {
+			t.Errorf("Expected body to contain %s, got %s", expected, w.Body.String())
+		}
+	})
+
+	t.Run("WildcardUsesDefault", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept", "*/*")
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+
+		expected := `{"error":"no such item"}`
+		if strings.TrimSpace(w.Body.String()) != expected {
+			t.Errorf("Expected body %s, got %s", expected, w.Body.String())
+		}
+	})
+
+	t.Run("NoMatchUsesDefault", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept", "text/html")
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+
+		expected := `{"error":"no such item"}`
+		if strings.TrimSpace(w.Body.String()) != expected {
+			t.Errorf("Expected body %s, got %s", expected, w.Body.String())
+		}
+	})
+}
+
+// localeResponder writes the error message prefixed with the request's
+// Accept-Language header.
+type localeResponder struct{}
+
+func (localeResponder) Error(w http.ResponseWriter, r *http.Request, err error, status int) error {
+	lang := "none"
+	if r != nil {
+		lang = r.Header.Get("Accept-Language")
+	}
+	w.WriteHeader(status)
+	_, werr := fmt.Fprintf(w, "[%s] %s", lang, err)
+	return werr
+}
+
+// legacyTextResponder uses the pre-request ErrorResponder signature.
+type legacyTextResponder struct{}
+
+func (legacyTextResponder) Error(w http.ResponseWriter, err error, status int) error {
+	w.WriteHeader(status)
+	_, werr := w.Write([]byte("legacy: " + err.Error()))
+	return werr
+}
+
+func TestErrorResponderRequest(t *testing.T) {
+	failing := func(_ http.ResponseWriter, _ *http.Request) error {
+		return httpx.NewStatusError(http.StatusBadRequest, errors.New("bad input"))
+	}
+
+	t.Run("ReadsRequestHeader", func(t *testing.T) {
+		handler := httpx.HandlerFunc(failing)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req = req.WithContext(httpx.ContextWithResponder(req.Context(), localeResponder{}))
+		req.Header.Set("Accept-Language", "fr")
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+
+		if w.Body.String() != "[fr] bad input" {
+			t.Errorf("Expected body '[fr] bad input', got '%s'", w.Body.String())
+		}
+	})
+
+	t.Run("NoRequestOutsideHandler", func(t *testing.T) {
+		w := httptest.NewRecorder()
+
+		err := localeResponder{}.Error(w, nil, errors.New("bad input"), http.StatusBadRequest)
+		if err != nil {
+			t.Fatalf("Error() returned error: %v", err)
+		}
+
+		if w.Body.String() != "[none] bad input" {
+			t.Errorf("Expected body '[none] bad input', got '%s'", w.Body.String())
+		}
+	})
+
+	t.Run("AdaptedLegacyResponder", func(t *testing.T) {
+		handler := httpx.HandlerFunc(failing)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		responder := httpx.AdaptResponder(legacyTextResponder{})
+		req = req.WithContext(httpx.ContextWithResponder(req.Context(), responder))
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
+		}
+		if w.Body.String() != "legacy: bad input" {
+			t.Errorf("Expected body 'legacy: bad input', got '%s'", w.Body.String())
+		}
+	})
+}
+
+func TestMessageResolver(t *testing.T) {
+	httpx.SetMessageResolver(func(r *http.Request, key string) string {
+		if r == nil || httpx.PreferredLanguage(r, []string{"en", "fr"}) != "fr" {
+			return ""
+		}
+		switch key {
+		case httpx.MessageNotFound:
+			return "ressource introuvable"
+		case httpx.MessageInternalError:
+			return "erreur interne du serveur"
+		}
+		return ""
+	})
+	defer httpx.SetMessageResolver(nil)
+
+	handler := httpx.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) error {
+		return httpx.NotFound(w, nil)
+	})
+
+	tests := []struct {
+		name     string
+		language string
+		expected string
+	}{
+		{"French", "fr-FR,fr;q=0.9", `{"error":"ressource introuvable"}`},
+		{"EnglishDefault", "en", `{"error":"resource not found"}`},
+		{"NoHeader", "", `{"error":"resource not found"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.language != "" {
+				req.Header.Set("Accept-Language", tt.language)
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			if strings.TrimSpace(w.Body.String()) != tt.expected {
+				t.Errorf("Expected body %s, got %s", tt.expected, w.Body.String())
+			}
+		})
+	}
+
+	t.Run("InternalError", func(t *testing.T) {
+		failing := httpx.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) error {
+			return errors.New("boom")
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept-Language", "fr")
+		w := httptest.NewRecorder()
+
+		failing.ServeHTTP(w, req)
+
+		expected := `{"error":"erreur interne du serveur: boom"}`
+		if strings.TrimSpace(w.Body.String()) != expected {
+			t.Errorf("Expected body %s, got %s", expected, w.Body.String())
+		}
+	})
+}
+
+// countingReader records how many bytes have been read from it.
+type countingReader struct {
+	r    io.Reader
+	read int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.read += n
+	return n, err
+}
+
+func TestDecodeMaxBytes(t *testing.T) {
+	body := `{"name":"` + strings.Repeat("x", 100) + `","value":1}`
+
+	decode := httpx.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) error {
+		var result testStruct
+		return httpx.DecodeJSON(r, &result, httpx.WithMaxBytes(32))
+	})
+
+	t.Run("DeclaredContentLength", func(t *testing.T) {
+		reader := &countingReader{r: strings.NewReader(body)}
+		req := httptest.NewRequest(http.MethodPost, "/", reader)
+		req.ContentLength = int64(len(body))
+		w := httptest.NewRecorder()
+
+		decode.ServeHTTP(w, req)
+
+		if w.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("Expected status code %d, got %d", http.StatusRequestEntityTooLarge, w.Code)
+		}
+		if reader.read != 0 {
+			t.Errorf("Expected body not to be read, got %d bytes read", reader.read)
+		}
+	})
+
+	t.Run("Chunked", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req.ContentLength = -1
+		req.TransferEncoding = []string{"chunked"}
+		w := httptest.NewRecorder()
+
+		decode.ServeHTTP(w, req)
+
+		if w.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("Expected status code %d, got %d", http.StatusRequestEntityTooLarge, w.Code)
+		}
+	})
+
+	t.Run("WithinLimit", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"name":"ok"}`))
+		var result testStruct
+
+		if err := httpx.DecodeJSON(req, &result, httpx.WithMaxBytes(32)); err != nil {
+			t.Errorf("DecodeJSON() returned error: %v", err)
+		}
+		if result.Name != "ok" {
+			t.Errorf("Expected name 'ok', got '%s'", result.Name)
+		}
+	})
+}
+
+func TestJSONWithHeaders(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	err := httpx.JSONWithHeaders(w, []string{"a", "b"}, http.StatusOK, map[string]string{
+		"X-Total-Count": "42",
+		"Link":          `</items?page=2>; rel="next"`,
+	})
+	if err != nil {
+		t.Fatalf("JSONWithHeaders() returned error: %v", err)
+	}
+
+	if w.Header().Get("X-Total-Count") != "42" {
+		t.Errorf("Expected X-Total-Count '42', got '%s'", w.Header().Get("X-Total-Count"))
+	}
+	if w.Header().Get("Link") != `</items?page=2>; rel="next"` {
+		t.Errorf("Expected Link header, got '%s'", w.Header().Get("Link"))
+	}
+	if w.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("Expected Content-Type 'application/json', got '%s'", w.Header().Get("Content-Type"))
+	}
+	if strings.TrimSpace(w.Body.String()) != `["a","b"]` {
+		t.Errorf("Expected body [\"a\",\"b\"], got %s", w.Body.String())
+	}
+}
+
+func TestErrAbort(t *testing.T) {
+	t.Run("AfterWritingResponse", func(t *testing.T) {
+		handler := httpx.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) error {
+			if err := httpx.Error(w, errors.New("unauthorized"), http.StatusUnauthorized); err != nil {
+				return err
+			}
+			return httpx.ErrAbort
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status code %d, got %d", http.StatusUnauthorized, w.Code)
+		}
+
+		expected := `{"error":"unauthorized"}`
+		if strings.TrimSpace(w.Body.String()) != expected {
+			t.Errorf("Expected body %s, got %s", expected, w.Body.String())
+		}
+	})
+
+	t.Run("Wrapped", func(t *testing.T) {
+		handler := httpx.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) error {
+			w.WriteHeader(http.StatusNoContent)
+			return fmt.Errorf("auth: %w", httpx.ErrAbort)
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNoContent {
+			t.Errorf("Expected status code %d, got %d", http.StatusNoContent, w.Code)
+		}
+		if w.Body.Len() != 0 {
+			t.Errorf("Expected empty body, got %s", w.Body.String())
+		}
+	})
+}
+
+func TestDecodeStream(t *testing.T) {
+	t.Run("NDJSON", func(t *testing.T) {
+		body := "{\"name\":\"a\",\"value\":1}\n{\"name\":\"b\",\"value\":2}\n{\"name\":\"c\",\"value\":3}\n"
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+
+		var items []testStruct
+		err := httpx.DecodeStream(req, func(decode func(v interface{}) error) error {
+			var item testStruct
+			if err := decode(&item); err != nil {
+				return err
+			}
+			items = append(items, item)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("DecodeStream() returned error: %v", err)
+		}
+
+		if len(items) != 3 {
+			t.Fatalf("Expected 3 callbacks, got %d", len(items))
+		}
+		if items[2].Name != "c" || items[2].Value != 3 {
+			t.Errorf("Expected third item {c 3}, got %+v", items[2])
+		}
+	})
+
+	t.Run("MalformedLine", func(t *testing.T) {
+		body := "{\"name\":\"a\"}\n{\"name\":\n"
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+
+		calls := 0
+		err := httpx.DecodeStream(req, func(decode func(v interface{}) error) error {
+			calls++
+			var item testStruct
+			return decode(&item)
+		})
+		if err == nil {
+			t.Errorf("Expected an error for a malformed line")
+		}
+		if calls != 2 {
+			t.Errorf("Expected 2 callbacks, got %d", calls)
+		}
+	})
+
+	t.Run("CallbackError", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("{}\n{}\n"))
+		stop := errors.New("stop")
+
+		err := httpx.DecodeStream(req, func(func(v interface{}) error) error {
+			return stop
+		})
+		if !errors.Is(err, stop) {
+			t.Errorf("Expected callback error, got %v", err)
+		}
+	})
+}
+
+func TestNDJSON(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	stream := httpx.NDJSON(w, http.StatusOK)
+	for i := 1; i <= 3; i++ {
+		if err := stream.Encode(testStruct{Name: fmt.Sprintf("item%d", i), Value: i}); err != nil {
+			t.Fatalf("Encode() returned error: %v", err)
+		}
+	}
+
+	if w.Header().Get("Content-Type") != "application/x-ndjson" {
+		t.Errorf("Expected Content-Type 'application/x-ndjson', got '%s'", w.Header().Get("Content-Type"))
+	}
+	if !w.Flushed {
+		t.Errorf("Expected response to be flushed")
+	}
+
+	lines := strings.Split(strings.TrimSuffix(w.Body.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 lines, got %d: %q", len(lines), w.Body.String())
+	}
+	for i, line := range lines {
+		var item testStruct
+		if err := json.Unmarshal([]byte(line), &item); err != nil {
+			t.Errorf("Line %d is not valid JSON: %v", i+1, err)
+		}
+		if item.Value != i+1 {
+			t.Errorf("Expected value %d on line %d, got %d", i+1, i+1, item.Value)
+		}
+	}
+}
+
+func TestNDJSONCancel(t *testing.T) {
+	t.Run("CanceledMidStream", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var encoded int
+		var streamErr error
+		handler := httpx.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) error {
+			stream := httpx.NDJSON(w, http.StatusOK)
+			for i := 0; i < 10; i++ {
+				if i == 2 {
+					cancel()
+				}
+				if streamErr = stream.Encode(testStruct{Value: i}); streamErr != nil {
+					return nil
+				}
+				encoded++
+			}
+			return nil
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+
+		if !errors.Is(streamErr, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", streamErr)
+		}
+		if encoded != 2 {
+			t.Errorf("Expected 2 values before cancellation, got %d", encoded)
+		}
+	})
+
+	t.Run("BlockedOnSlowClient", func(t *testing.T) {
+		done := make(chan error, 1)
+		handler := httpx.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) error {
+			stream := httpx.NDJSON(w, http.StatusOK)
+			chunk := strings.Repeat("x", 64<<10)
+			for {
+				if err := stream.Encode(chunk); err != nil {
+					done <- err
+					return nil
+				}
+			}
+		})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), 100*time.Millisecond)
+			defer cancel()
+			handler.ServeHTTP(w, r.WithContext(ctx))
+		}))
+		defer server.Close()
+
+		// Read the headers but never the body, so the server's writes block.
+		resp, err := http.Get(server.URL)
+		if err != nil {
+			t.Fatalf("GET returned error: %v", err)
+		}
+		defer resp.Body.Close()
+
+		select {
+		case err := <-done:
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Expected the stream to stop after the context ended")
+		}
+	})
+
+	// Test case: the default router stack lets the deadline reach the connection
+	t.Run("BlockedOnSlowClientBehindRouter", func(t *testing.T) {
+		done := make(chan error, 1)
+		router := vibe.New(vibe.WithTimeout(100 * time.Millisecond))
+		router.Get("/stream", func(w http.ResponseWriter, _ *http.Request) error {
+			stream := httpx.NDJSON(w, http.StatusOK)
+			chunk := strings.Repeat("x", 64<<10)
+			for {
+				if err := stream.Encode(chunk); err != nil {
+					done <- err
+					return nil
+				}
+			}
+		})
+		server := httptest.NewServer(router)
+		defer server.Close()
+
+		// Read the headers but never the body, so the server's writes block.
+		resp, err := http.Get(server.URL + "/stream")
+		if err != nil {
+			t.Fatalf("GET returned error: %v", err)
+		}
+		defer resp.Body.Close()
+
+		select {
+		case err := <-done:
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Expected the stream to stop after the context ended")
+		}
+	})
+}
+
+func TestTimeLeft(t *testing.T) {
+	t.Run("WithTimeout", func(t *testing.T) {
+		var first, second time.Duration
+		var ok bool
+
+		handler := httpx.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			first, ok = httpx.TimeLeft(r)
+			time.Sleep(10 * time.Millisecond)
+			second, _ = httpx.TimeLeft(r)
+			w.WriteHeader(http.StatusOK)
+			return nil
+		})
+
+		wrapped := middleware.WithTimeout(100 * time.Millisecond)(handler)
+		wrapped.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+		if !ok {
+			t.Fatalf("Expected request to have a deadline")
+		}
+		if first <= 0 || first > 100*time.Millisecond {
+			t.Errorf("Expected time left in (0, 100ms], got %v", first)
+		}
+		if second >= first {
+			t.Errorf("Expected time left to shrink, got %v then %v", first, second)
+		}
+	})
+
+	t.Run("NoDeadline", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+		if _, ok := httpx.TimeLeft(req); ok {
+			t.Errorf("Expected no deadline")
+		}
+	})
+}
+
+type xmlPartner struct {
+	XMLName xml.Name `xml:"partner"`
+	ID      int      `xml:"id,attr"`
+	Name    string   `xml:"name"`
+}
+
+func TestXML(t *testing.T) {
+	t.Run("Decode", func(t *testing.T) {
+		body := `<?xml version="1.0"?><partner id="7"><name>Acme</name></partner>`
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/xml")
+
+		var partner xmlPartner
+		if err := httpx.DecodeXML(req, &partner); err != nil {
+			t.Fatalf("DecodeXML() returned error: %v", err)
+		}
+		if partner.ID != 7 || partner.Name != "Acme" {
+			t.Errorf("Expected partner {7 Acme}, got %+v", partner)
+		}
+	})
+
+	t.Run("DecodeInvalid", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`<partner><name>`))
+
+		var partner xmlPartner
+		err := httpx.DecodeXML(req, &partner)
+		if err == nil || !strings.Contains(err.Error(), "failed to decode XML") {
+			t.Errorf("Expected decode error, got %v", err)
+		}
+	})
+
+	t.Run("DecodeNonXMLContentType", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		var partner xmlPartner
+		err := httpx.DecodeXML(req, &partner)
+		var statusErr *httpx.StatusError
+		if !errors.As(err, &statusErr) || statusErr.Status != http.StatusUnsupportedMediaType {
+			t.Errorf("Expected a %d *httpx.StatusError for non-XML Content-Type, got %v", http.StatusUnsupportedMediaType, err)
+		}
+	})
+
+	t.Run("Encode", func(t *testing.T) {
+		w := httptest.NewRecorder()
+
+		if err := httpx.XML(w, xmlPartner{ID: 7, Name: "Acme"}, http.StatusCreated); err != nil {
+			t.Fatalf("XML() returned error: %v", err)
+		}
+
+		if w.Code != http.StatusCreated {
+			t.Errorf("Expected status code %d, got %d", http.StatusCreated, w.Code)
+		}
+		if !strings.HasPrefix(w.Header().Get("Content-Type"), "application/xml") {
+			t.Errorf("Expected Content-Type 'application/xml', got '%s'", w.Header().Get("Content-Type"))
+		}
+
+		var partner xmlPartner
+		if err := xml.Unmarshal(w.Body.Bytes(), &partner); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if partner.ID != 7 || partner.Name != "Acme" {
+			t.Errorf("Expected partner {7 Acme}, got %+v", partner)
+		}
+	})
+}
+
+func TestCSV(t *testing.T) {
+	t.Run("Rows", func(t *testing.T) {
+		w := httptest.NewRecorder()
+
+		err := httpx.CSV(w, "report.csv", [][]string{
+			{"name", "note"},
+			{"Acme, Inc.", `said "hi"`},
+		})
+		if err != nil {
+			t.Fatalf("CSV() returned error: %v", err)
+		}
+
+		if !strings.HasPrefix(w.Header().Get("Content-Type"), "text/csv") {
+			t.Errorf("Expected Content-Type 'text/csv', got '%s'", w.Header().Get("Content-Type"))
+		}
+		if w.Header().Get("Content-Disposition") != `attachment; filename=report.csv` {
+			t.Errorf("Expected attachment disposition, got '%s'", w.Header().Get("Content-Disposition"))
+		}
+
+		expected := "name,note\n\"Acme, Inc.\",\"said \"\"hi\"\"\"\n"
+		if w.Body.String() != expected {
+			t.Errorf("Expected body %q, got %q", expected, w.Body.String())
+		}
+	})
+
+	t.Run("Structs", func(t *testing.T) {
+		type sale struct {
+			Customer string  `csv:"customer"`
+			Amount   float64 `csv:"amount"`
+			Internal string  `csv:"-"`
+			Region   string
+		}
+
+		w := httptest.NewRecorder()
+
+		err := httpx.CSVStructs(w, "sales.csv", []sale{
+			{Customer: "Acme, Inc.", Amount: 12.5, Internal: "x", Region: "EU"},
+		})
+		if err != nil {
+			t.Fatalf("CSVStructs() returned error: %v", err)
+		}
+
+		expected := "customer,amount,Region\n\"Acme, Inc.\",12.5,EU\n"
+		if w.Body.String() != expected {
+			t.Errorf("Expected body %q, got %q", expected, w.Body.String())
+		}
+	})
+
+	t.Run("StructsNotASlice", func(t *testing.T) {
+		w := httptest.NewRecorder()
+
+		if err := httpx.CSVStructs(w, "bad.csv", "nope"); err == nil {
+			t.Errorf("Expected error for non-slice input")
+		}
+	})
+}
+
+func TestJSONReusesEncoders(t *testing.T) {
+	// Encoders are pooled; responses must not leak data into one another,
+	// including after an encoding failure left a partial value behind.
+	values := []interface{}{
+		map[string]string{"first": strings.Repeat("x", 100)},
+		map[string]interface{}{"bad": func() {}},
+		[]int{1, 2, 3},
+		"short",
+	}
+	expected := []string{
+		`{"first":"` + strings.Repeat("x", 100) + `"}`,
+		"",
+		`[1,2,3]`,
+		`"short"`,
+	}
+
+	for i, v := range values {
+		w := httptest.NewRecorder()
+		err := httpx.JSON(w, v, http.StatusOK)
+		if expected[i] == "" {
+			if err == nil {
+				t.Errorf("Expected encoding error for value %d", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("JSON() returned error for value %d: %v", i, err)
+		}
+		if strings.TrimSpace(w.Body.String()) != expected[i] {
+			t.Errorf("Expected body %s, got %s", expected[i], w.Body.String())
+		}
+	}
+
+	t.Run("Concurrent", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				w := httptest.NewRecorder()
+				httpx.JSON(w, map[string]int{"n": i}, http.StatusOK)
+				if strings.TrimSpace(w.Body.String()) != fmt.Sprintf(`{"n":%d}`, i) {
+					t.Errorf("Expected body {\"n\":%d}, got %s", i, w.Body.String())
+				}
+			}(i)
+		}
+		wg.Wait()
+	})
+}
+
+func BenchmarkJSON(b *testing.B) {
+	data := map[string]interface{}{"name": "vibe", "values": []int{1, 2, 3, 4, 5}}
+	w := httptest.NewRecorder()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		w.Body.Reset()
+		if err := httpx.JSON(w, data, http.StatusOK); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeJSON(b *testing.B) {
+	body := `{"name":"vibe","value":42}`
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		var v testStruct
+		if err := httpx.DecodeJSON(req, &v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestParams(t *testing.T) {
+	var id, again int
+	var slug string
+	var badErr error
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /posts/{id}/{slug}", func(_ http.ResponseWriter, r *http.Request) {
+		p := httpx.Params(r)
+		id, _ = p.Int("id")
+		again, _ = p.Int("id")
+		slug = p.String("slug")
+		_, badErr = p.Int("slug")
+	})
+
+	mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/posts/42/hello", nil))
+
+	if id != 42 || again != 42 {
+		t.Errorf("Expected id 42 on both reads, got %d and %d", id, again)
+	}
+	if slug != "hello" {
+		t.Errorf("Expected slug 'hello', got '%s'", slug)
+	}
+	if badErr == nil || !strings.Contains(badErr.Error(), `"slug"`) {
+		t.Errorf("Expected error naming the parameter, got %v", badErr)
+	}
+
+	// Test case: the accessors can be called on the result directly
+	t.Run("Chained", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/posts/7", nil)
+		req.SetPathValue("id", "7")
+
+		n, err := httpx.Params(req).Int("id")
+		if err != nil || n != 7 {
+			t.Errorf("Expected 7, got %d (%v)", n, err)
+		}
+	})
+}
+
+func BenchmarkParams(b *testing.B) {
+	req := httptest.NewRequest(http.MethodGet, "/posts/42", nil)
+	req.SetPathValue("id", "42")
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		p := httpx.Params(req)
+		for j := 0; j < 4; j++ {
+			if n, err := p.Int("id"); err != nil || n != 42 {
+				b.Fatalf("Expected 42, got %d (%v)", n, err)
+			}
+		}
+	}
+}
+
+func TestAdaptErrorMiddleware(t *testing.T) {
+	var logged []string
+	logErrors := func(next httpx.HandlerFunc) httpx.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			err := next(w, r)
+			if err != nil {
+				logged = append(logged, err.Error())
+			}
+			return err
+		}
+	}
+
+	passThrough := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	failing := httpx.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) error {
+		return errors.New("boom")
+	})
+
+	// Test case: the error is seen by the middleware, then becomes a 500
+	t.Run("LogsBeforeConversion", func(t *testing.T) {
+		logged = nil
+		handler := httpx.AdaptErrorMiddleware(logErrors)(passThrough(failing))
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+
+		if len(logged) != 1 || logged[0] != "boom" {
+			t.Errorf("Expected logged error 'boom', got %v", logged)
+		}
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("Expected status code %d, got %d", http.StatusInternalServerError, w.Code)
+		}
+
+		expected := `{"error":"internal server error: boom"}`
+		if strings.TrimSpace(w.Body.String()) != expected {
+			t.Errorf("Expected body %s, got %s", expected, w.Body.String())
+		}
+	})
+
+	// Test case: the middleware can translate the error
+	t.Run("Translates", func(t *testing.T) {
+		toConflict := func(next httpx.HandlerFunc) httpx.HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request) error {
+				if err := next(w, r); err != nil {
+					return httpx.NewStatusError(http.StatusConflict, err)
+				}
+				return nil
+			}
+		}
+		handler := httpx.AdaptErrorMiddleware(toConflict)(failing)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusConflict {
+			t.Errorf("Expected status code %d, got %d", http.StatusConflict, w.Code)
+		}
+	})
+
+	// Test case: nested error middleware each see the error once
+	t.Run("Nested", func(t *testing.T) {
+		logged = nil
+		handler := httpx.AdaptErrorMiddleware(logErrors)(
+			passThrough(httpx.AdaptErrorMiddleware(logErrors)(failing)))
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+
+		if len(logged) != 2 {
+			t.Errorf("Expected error to be logged twice, got %v", logged)
+		}
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("Expected status code %d, got %d", http.StatusInternalServerError, w.Code)
+		}
+	})
+
+	// Test case: successful requests pass through untouched
+	t.Run("NoError", func(t *testing.T) {
+		logged = nil
+		ok := httpx.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) error {
+			w.WriteHeader(http.StatusNoContent)
+			return nil
+		})
+		handler := httpx.AdaptErrorMiddleware(logErrors)(ok)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+
+		if len(logged) != 0 || w.Code != http.StatusNoContent {
+			t.Errorf("Expected 204 and nothing logged, got %d and %v", w.Code, logged)
+		}
+	})
+}
+
+func TestEmptyCollections(t *testing.T) {
+	type page struct {
+		Items []string          `json:"items"`
+		Meta  map[string]string `json:"meta"`
+		Next  *string           `json:"next"`
+	}
+
+	t.Run("Off by default", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		if err := httpx.JSON(w, page{}, http.StatusOK); err != nil {
+			t.Fatalf("JSON() returned error: %v", err)
+		}
+
+		expected := `{"items":null,"meta":null,"next":null}`
+		if strings.TrimSpace(w.Body.String()) != expected {
+			t.Errorf("Expected body %s, got %s", expected, w.Body.String())
+		}
+	})
+
+	t.Run("Normalization on", func(t *testing.T) {
+		handler := httpx.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) error {
+			return httpx.JSON(w, &page{}, http.StatusOK)
+		})
+
+		tests := []struct {
+			name    string
+			handler http.Handler
+			req     *http.Request
+		}{
+			{"Context", handler, httptest.NewRequest(http.MethodGet, "/", nil).
+				WithContext(httpx.ContextWithEmptyCollections(context.Background()))},
+			{"Middleware", middleware.EmptyCollections()(handler), httptest.NewRequest(http.MethodGet, "/", nil)},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				w := httptest.NewRecorder()
+				tt.handler.ServeHTTP(w, tt.req)
+
+				expected := `{"items":[],"meta":{},"next":null}`
+				if strings.TrimSpace(w.Body.String()) != expected {
+					t.Errorf("Expected body %s, got %s", expected, w.Body.String())
+				}
+			})
+		}
+	})
+
+	t.Run("Top-level nil slice", func(t *testing.T) {
+		var items []int
+		data, err := json.Marshal(httpx.EmptyCollections(items))
+		if err != nil {
+			t.Fatalf("Marshal() returned error: %v", err)
+		}
+		if string(data) != "[]" {
+			t.Errorf("Expected [], got %s", data)
+		}
+	})
+
+	t.Run("Does not modify the original", func(t *testing.T) {
+		original := &page{}
+		httpx.EmptyCollections(original)
+		if original.Items != nil {
+			t.Errorf("Expected original Items to stay nil, got %#v", original.Items)
+		}
+	})
+}
+
+func TestRawBody(t *testing.T) {
+	t.Run("Body remains readable", func(t *testing.T) {
+		input := `{"name":"test","value":123}`
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(input))
+
+		body, err := httpx.RawBody(req)
+		if err != nil {
+			t.Fatalf("RawBody() returned error: %v", err)
+		}
+		if string(body) != input {
+			t.Errorf("Expected body %s, got %s", input, body)
+		}
+
+		var result testStruct
+		if err := httpx.DecodeJSON(req, &result); err != nil {
+			t.Fatalf("DecodeJSON() after RawBody() returned error: %v", err)
+		}
+		if result.Name != "test" || result.Value != 123 {
+			t.Errorf("Expected body to be decoded after RawBody, got %+v", result)
+		}
+
+		again, err := req.GetBody()
+		if err != nil {
+			t.Fatalf("GetBody() returned error: %v", err)
+		}
+		if forwarded, _ := io.ReadAll(again); string(forwarded) != input {
+			t.Errorf("Expected GetBody to return %s, got %s", input, forwarded)
+		}
+	})
+
+	t.Run("Too large", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(strings.Repeat("a", 64)))
+		req.ContentLength = -1
+
+		_, err := httpx.RawBody(req, httpx.WithMaxBytes(32))
+
+		var statusErr *httpx.StatusError
+		if !errors.As(err, &statusErr) || statusErr.Status != http.StatusRequestEntityTooLarge {
+			t.Errorf("Expected 413 StatusError, got %v", err)
+		}
+	})
+}
+
+func TestJSONNoSniff(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(w http.ResponseWriter) error
+	}{
+		{"JSON", func(w http.ResponseWriter) error {
+			return httpx.JSON(w, "<script>alert(1)</script>", http.StatusOK)
+		}},
+		{"Error", func(w http.ResponseWriter) error {
+			return httpx.BadRequest(w, errors.New("<b>bad</b>"))
+		}},
+		{"NDJSON", func(w http.ResponseWriter) error {
+			return httpx.NDJSON(w, http.StatusOK).Encode("<html>")
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			if err := tt.write(w); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if got := w.Header().Get("X-Content-Type-Options"); got != "nosniff" {
+				t.Errorf("Expected X-Content-Type-Options 'nosniff', got '%s'", got)
+			}
+		})
+	}
+}
+
+func TestJSONHead(t *testing.T) {
+	handler := httpx.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		return httpx.JSON(w, map[string]string{"name": "vibe"}, http.StatusOK)
+	})
+
+	get := httptest.NewRecorder()
+	handler.ServeHTTP(get, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	head := httptest.NewRecorder()
+	handler.ServeHTTP(head, httptest.NewRequest(http.MethodHead, "/", nil))
+
+	if head.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, head.Code)
+	}
+	if head.Body.Len() != 0 {
+		t.Errorf("Expected empty body, got '%s'", head.Body.String())
+	}
+	want := strconv.Itoa(get.Body.Len())
+	if got := head.Header().Get("Content-Length"); got != want {
+		t.Errorf("Expected Content-Length '%s', got '%s'", want, got)
+	}
+	if got := head.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("Expected Content-Type 'application/json', got '%s'", got)
+	}
+}
@@ -1,30 +1,264 @@
 package httpx
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"mime"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
 )
 
+// IsJSONContentType reports whether contentType denotes JSON, i.e. is
+// application/json or a structured +json type such as application/problem+json.
+// Parameters such as charset are ignored.
+func IsJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// IsJSON reports whether the request's Content-Type denotes JSON.
+func IsJSON(r *http.Request) bool {
+	return IsJSONContentType(r.Header.Get("Content-Type"))
+}
+
+// DecodeOption configures DecodeJSON.
+type DecodeOption func(*decodeConfig)
+
+// decodeConfig holds the configuration for DecodeJSON.
+type decodeConfig struct {
+	maxBytes int64
+	strict   bool
+}
+
+// WithMaxBytes limits the request body DecodeJSON or RawBody accepts to n
+// bytes. A request whose Content-Length exceeds the limit is rejected before
+// any of the body is read; a chunked request is cut off once the limit is
+// reached. Either way a *StatusError with 413 Request Entity Too Large is
+// returned, which a HandlerFunc reports with that status.
+//
+// Example:
+//
+//	if err := httpx.DecodeJSON(r, &input, httpx.WithMaxBytes(1<<20)); err != nil {
+//		return err
+//	}
+func WithMaxBytes(n int64) DecodeOption {
+	return func(c *decodeConfig) {
+		c.maxBytes = n
+	}
+}
+
+// WithStrict makes DecodeJSON reject unknown fields and any data after the
+// JSON value. Trailing whitespace, such as the newline many clients append,
+// is still accepted.
+//
+// Example:
+//
+//	if err := httpx.DecodeJSON(r, &input, httpx.WithStrict()); err != nil {
+//		return err
+//	}
+func WithStrict() DecodeOption {
+	return func(c *decodeConfig) {
+		c.strict = true
+	}
+}
+
 // DecodeJSON decodes the JSON request body into the provided value.
-func DecodeJSON(r *http.Request, v interface{}) error {
+// Requests that declare a Content-Type other than JSON are rejected with a
+// *StatusError with 415 Unsupported Media Type; a missing Content-Type is
+// accepted.
+// If the request's context ends while the body is being read, e.g. because a
+// slow upload outlasts the timeout, the body is closed and DecodeJSON returns
+// an error wrapping the context's error. A server request body only gives
+// up a stalled read once the connection's read deadline passes, which
+// middleware.WithTimeout sets when the timeout expires.
+func DecodeJSON(r *http.Request, v interface{}, options ...DecodeOption) error {
+	cfg := &decodeConfig{}
+	for _, option := range options {
+		option(cfg)
+	}
+
 	if r.Body == nil {
 		return errors.New("request body is empty")
 	}
+	if ct := r.Header.Get("Content-Type"); ct != "" && !IsJSONContentType(ct) {
+		return unsupportedMediaType(ct)
+	}
 	defer r.Body.Close()
 
-	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+	ctx := r.Context()
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("failed to decode JSON: %w", err)
+	}
+	if ctx.Done() != nil {
+		stop := context.AfterFunc(ctx, func() {
+			r.Body.Close()
+		})
+		defer stop()
+	}
+
+	body := r.Body
+	if cfg.maxBytes > 0 {
+		if r.ContentLength > cfg.maxBytes {
+			return tooLarge(cfg.maxBytes)
+		}
+		body = http.MaxBytesReader(nil, r.Body, cfg.maxBytes)
+	}
+
+	dec := json.NewDecoder(body)
+	if cfg.strict {
+		dec.DisallowUnknownFields()
+	}
+
+	var maxErr *http.MaxBytesError
+	if err := dec.Decode(v); err != nil {
+		if errors.As(err, &maxErr) {
+			return tooLarge(cfg.maxBytes)
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return fmt.Errorf("failed to decode JSON: %w", ctxErr)
+		}
 		return fmt.Errorf("failed to decode JSON: %w", err)
 	}
 
+	if cfg.strict {
+		// Token skips whitespace, so only another value or stray
+		// characters make it return something other than io.EOF.
+		if _, err := dec.Token(); err != io.EOF {
+			if errors.As(err, &maxErr) {
+				return tooLarge(cfg.maxBytes)
+			}
+			return errors.New("failed to decode JSON: unexpected data after the JSON value")
+		}
+	}
+
 	return nil
 }
 
+// unsupportedMediaType returns the error reported for a request body whose
+// Content-Type the decoder does not accept.
+func unsupportedMediaType(contentType string) error {
+	return NewStatusError(http.StatusUnsupportedMediaType,
+		fmt.Errorf("unsupported Content-Type %q", contentType))
+}
+
+// tooLarge returns the error reported for a body over the limit.
+func tooLarge(limit int64) error {
+	return NewStatusError(http.StatusRequestEntityTooLarge,
+		fmt.Errorf("request body exceeds %d bytes", limit))
+}
+
 // JSON sets the Content-Type to "application/json", sets the provided status code,
-// and encodes the data as JSON.
+// and encodes the data as JSON. It also sets "X-Content-Type-Options: nosniff",
+// so that browsers never sniff a JSON body as HTML.
+// The data is encoded before anything is written, so if encoding fails the
+// error is returned and the response is left untouched for the caller to
+// report, e.g. as a 500.
+//
+// For HEAD requests, which GET routes also serve, the body is encoded only
+// to set Content-Length and is not written, as long as w is the
+// ResponseWriter passed to a HandlerFunc.
 func JSON(w http.ResponseWriter, data interface{}, statusCode int) error {
+	return JSONWithHeaders(w, data, statusCode, nil)
+}
+
+// JSONWithHeaders is like JSON but also sets the given response headers,
+// such as X-Total-Count or Link. The headers are set before the status code
+// is written, which is the only point at which they take effect.
+//
+// Example:
+//
+//	return httpx.JSONWithHeaders(w, users, http.StatusOK, map[string]string{
+//		"X-Total-Count": strconv.Itoa(total),
+//	})
+func JSONWithHeaders(w http.ResponseWriter, data interface{}, statusCode int, headers map[string]string) error {
+	r := requestFor(w)
+	data = transform(r, data, statusCode)
+
+	e := getJSONEncoder()
+	defer putJSONEncoder(e)
+
+	if err := e.enc.Encode(data); err != nil {
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
+
+	for key, value := range headers {
+		w.Header().Set(key, value)
+	}
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if r != nil && r.Method == http.MethodHead {
+		w.Header().Set("Content-Length", strconv.Itoa(e.buf.Len()))
+		w.WriteHeader(statusCode)
+		return nil
+	}
 	w.WriteHeader(statusCode)
-	return json.NewEncoder(w).Encode(data)
+	_, err := w.Write(e.buf.Bytes())
+	return err
+}
+
+// maxPooledBuffer is the capacity above which encoding buffers are dropped
+// rather than returned to their pool, so that one large response does not
+// pin its memory for the lifetime of the process.
+const maxPooledBuffer = 64 << 10
+
+// jsonEncoder is a reusable JSON encoder writing into its own buffer.
+type jsonEncoder struct {
+	buf bytes.Buffer
+	enc *json.Encoder
+}
+
+// jsonEncoderPool recycles encoders across responses to reduce allocations.
+// Decoders are not pooled: a json.Decoder cannot be pointed at a new reader.
+var jsonEncoderPool = sync.Pool{
+	New: func() interface{} {
+		e := &jsonEncoder{}
+		e.enc = json.NewEncoder(&e.buf)
+		return e
+	},
+}
+
+// getJSONEncoder returns an encoder with an empty buffer from the pool.
+func getJSONEncoder() *jsonEncoder {
+	e := jsonEncoderPool.Get().(*jsonEncoder)
+	e.buf.Reset()
+	return e
+}
+
+// putJSONEncoder returns e to the pool unless its buffer grew too large.
+func putJSONEncoder(e *jsonEncoder) {
+	if e.buf.Cap() > maxPooledBuffer {
+		return
+	}
+	jsonEncoderPool.Put(e)
+}
+
+// bufferPool recycles the buffers used to encode XML and CSV responses.
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+// getBuffer returns an empty buffer from the pool.
+func getBuffer() *bytes.Buffer {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+// putBuffer returns buf to the pool unless it grew too large.
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBuffer {
+		return
+	}
+	bufferPool.Put(buf)
 }
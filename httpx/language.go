@@ -0,0 +1,98 @@
+package httpx
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// languageRange is a single entry of an Accept-Language header.
+type languageRange struct {
+	tag     string
+	quality float64
+}
+
+// parseAcceptLanguage parses an Accept-Language header value into ranges
+// ordered by descending quality. Entries with a quality of zero are dropped.
+func parseAcceptLanguage(header string) []languageRange {
+	var ranges []languageRange
+
+	for _, part := range strings.Split(header, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		tag, params, _ := strings.Cut(part, ";")
+		quality := 1.0
+		for _, param := range strings.Split(params, ";") {
+			key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok || strings.TrimSpace(key) != "q" {
+				continue
+			}
+			q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				q = 0
+			}
+			quality = q
+		}
+
+		if quality <= 0 {
+			continue
+		}
+		ranges = append(ranges, languageRange{
+			tag:     strings.ToLower(strings.TrimSpace(tag)),
+			quality: quality,
+		})
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].quality > ranges[j].quality
+	})
+
+	return ranges
+}
+
+// matchLanguage returns the first supported language matching tag.
+// An exact match is preferred, then a match on the primary subtag in
+// either direction (e.g. "en" matches "en-US" and vice versa).
+func matchLanguage(tag string, supported []string) (string, bool) {
+	if tag == "*" {
+		return supported[0], true
+	}
+
+	for _, lang := range supported {
+		if strings.EqualFold(lang, tag) {
+			return lang, true
+		}
+	}
+
+	primary, _, _ := strings.Cut(tag, "-")
+	for _, lang := range supported {
+		langPrimary, _, _ := strings.Cut(strings.ToLower(lang), "-")
+		if langPrimary == primary {
+			return lang, true
+		}
+	}
+
+	return "", false
+}
+
+// PreferredLanguage returns the supported language that best matches the
+// request's Accept-Language header, honouring quality values and the "*"
+// wildcard. If nothing matches, the first supported language is returned
+// as the default. An empty string is returned if supported is empty.
+func PreferredLanguage(r *http.Request, supported []string) string {
+	if len(supported) == 0 {
+		return ""
+	}
+
+	for _, lr := range parseAcceptLanguage(r.Header.Get("Accept-Language")) {
+		if lang, ok := matchLanguage(lr.tag, supported); ok {
+			return lang
+		}
+	}
+
+	return supported[0]
+}
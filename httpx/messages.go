@@ -0,0 +1,78 @@
+package httpx
+
+import (
+	"net/http"
+	"sync"
+)
+
+// Keys identifying the built-in error messages passed to a MessageResolver.
+const (
+	MessageNotFound      = "not_found"
+	MessageInternalError = "internal_server_error"
+	MessageUnknownError  = "unknown_error"
+)
+
+// defaultMessages holds the English built-in error messages.
+var defaultMessages = map[string]string{
+	MessageNotFound:      "resource not found",
+	MessageInternalError: "internal server error",
+	MessageUnknownError:  "unknown error",
+}
+
+// MessageResolver returns the text for the built-in message key in the
+// language of r. Returning an empty string falls back to the English default.
+// r is nil when the message is produced outside a HandlerFunc.
+type MessageResolver func(r *http.Request, key string) string
+
+var (
+	resolverMu      sync.RWMutex
+	messageResolver MessageResolver
+)
+
+// SetMessageResolver sets the function used to localize built-in error
+// messages such as "resource not found". Pass nil to restore the English
+// defaults.
+//
+// Example:
+//
+//	httpx.SetMessageResolver(func(r *http.Request, key string) string {
+//		if httpx.PreferredLanguage(r, []string{"en", "fr"}) == "fr" && key == httpx.MessageNotFound {
+//			return "ressource introuvable"
+//		}
+//		return ""
+//	})
+func SetMessageResolver(resolver MessageResolver) {
+	resolverMu.Lock()
+	defer resolverMu.Unlock()
+	messageResolver = resolver
+}
+
+// Message returns the built-in message for key, localized for r by the
+// resolver set with SetMessageResolver, or in English otherwise.
+func Message(r *http.Request, key string) string {
+	resolverMu.RLock()
+	resolver := messageResolver
+	resolverMu.RUnlock()
+
+	if resolver != nil {
+		if message := resolver(r, key); message != "" {
+			return message
+		}
+	}
+	return defaultMessages[key]
+}
+
+// requestFor returns the request recorded on w by HandlerFunc, or nil.
+func requestFor(w http.ResponseWriter) *http.Request {
+	for w != nil {
+		if rw, ok := w.(*ResponseWriter); ok && rw.request != nil {
+			return rw.request
+		}
+		uw, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			break
+		}
+		w = uw.Unwrap()
+	}
+	return nil
+}
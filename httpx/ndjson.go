@@ -0,0 +1,128 @@
+package httpx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// DecodeStream decodes a request body holding a stream of JSON values, such
+// as newline-delimited JSON (NDJSON), one value at a time. fn is called once
+// per value and receives a decode function that decodes the current value
+// into v, so large uploads can be processed without loading the whole body.
+// Decoding stops at the end of the body or at the first error, which is
+// returned; an error returned by fn is returned unchanged. If the request's
+// context is canceled, decoding stops with the context's error.
+//
+// Example:
+//
+//	err := httpx.DecodeStream(r, func(decode func(v interface{}) error) error {
+//		var event Event
+//		if err := decode(&event); err != nil {
+//			return err
+//		}
+//		return store.Insert(r.Context(), event)
+//	})
+func DecodeStream(r *http.Request, fn func(decode func(v interface{}) error) error) error {
+	if r.Body == nil {
+		return errors.New("request body is empty")
+	}
+	defer r.Body.Close()
+
+	dec := json.NewDecoder(r.Body)
+	for dec.More() {
+		if err := r.Context().Err(); err != nil {
+			return err
+		}
+
+		decoded := false
+		decode := func(v interface{}) error {
+			if decoded {
+				return errors.New("value already decoded")
+			}
+			decoded = true
+			if err := dec.Decode(v); err != nil {
+				return fmt.Errorf("failed to decode JSON: %w", err)
+			}
+			return nil
+		}
+
+		if err := fn(decode); err != nil {
+			return err
+		}
+
+		// Skip values fn chose not to decode.
+		if !decoded {
+			var skip json.RawMessage
+			if err := decode(&skip); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// NDJSONEncoder writes a stream of JSON values as newline-delimited JSON.
+type NDJSONEncoder struct {
+	w   http.ResponseWriter
+	ctx context.Context
+	enc *json.Encoder
+}
+
+// NDJSON starts a newline-delimited JSON response with the given status and
+// the "application/x-ndjson" Content-Type. Each value passed to Encode on
+// the returned encoder is written on its own line and flushed immediately,
+// so clients can process results as they arrive.
+//
+// Once the request's context is canceled, because the client went away or
+// the request timed out, Encode stops writing and returns the context's
+// error, and a write blocked on a slow client is interrupted. The context is
+// known when w was passed through a HandlerFunc.
+//
+// Example:
+//
+//	stream := httpx.NDJSON(w, http.StatusOK)
+//	for rows.Next() {
+//		if err := stream.Encode(row); err != nil {
+//			return err
+//		}
+//	}
+func NDJSON(w http.ResponseWriter, status int) *NDJSONEncoder {
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	ctx := context.Background()
+	if r := requestFor(w); r != nil {
+		ctx = r.Context()
+	}
+	return &NDJSONEncoder{w: w, ctx: ctx, enc: json.NewEncoder(w)}
+}
+
+// Encode writes v as a single line of JSON and flushes it to the client.
+func (e *NDJSONEncoder) Encode(v interface{}) error {
+	if err := e.ctx.Err(); err != nil {
+		return err
+	}
+
+	// Unblock a write stuck on a full connection buffer when the context
+	// ends; the connection is not going to be reused anyway.
+	stop := context.AfterFunc(e.ctx, func() {
+		_ = http.NewResponseController(e.w).SetWriteDeadline(time.Now())
+	})
+	defer stop()
+
+	if err := e.enc.Encode(v); err != nil {
+		if ctxErr := e.ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
+	if f, ok := e.w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
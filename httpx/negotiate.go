@@ -0,0 +1,83 @@
+package httpx
+
+import (
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// mediaRange is a single entry of an Accept header.
+type mediaRange struct {
+	mediaType string
+	quality   float64
+}
+
+// parseAccept parses an Accept header value into media ranges ordered by
+// descending quality. Ranges with a quality of zero are dropped.
+func parseAccept(header string) []mediaRange {
+	var ranges []mediaRange
+
+	for _, part := range strings.Split(header, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			quality, err = strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+		}
+		if quality <= 0 {
+			continue
+		}
+
+		ranges = append(ranges, mediaRange{mediaType: mediaType, quality: quality})
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].quality > ranges[j].quality
+	})
+
+	return ranges
+}
+
+// matches reports whether the media range admits mediaType.
+func (m mediaRange) matches(mediaType string) bool {
+	if m.mediaType == "*/*" || strings.EqualFold(m.mediaType, mediaType) {
+		return true
+	}
+	rangeType, rangeSubtype, _ := strings.Cut(m.mediaType, "/")
+	typ, _, _ := strings.Cut(mediaType, "/")
+	return rangeSubtype == "*" && strings.EqualFold(rangeType, typ)
+}
+
+// NegotiateContentType returns the offered media type the request's Accept
+// header prefers, honouring quality values and wildcards such as "*/*" and
+// "text/*". Offers are tried in order for each range, so earlier offers win
+// ties. If the request has no Accept header the first offer is returned; if
+// no offer is acceptable an empty string is returned.
+func NegotiateContentType(r *http.Request, offers []string) string {
+	if len(offers) == 0 {
+		return ""
+	}
+
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		return offers[0]
+	}
+
+	for _, mr := range parseAccept(accept) {
+		for _, offer := range offers {
+			if mr.matches(offer) {
+				return offer
+			}
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,43 @@
+package httpx
+
+import "net/http"
+
+// PageEnvelope is the JSON envelope written by Paginated.
+type PageEnvelope struct {
+	Data       interface{} `json:"data"`
+	Page       int         `json:"page"`
+	PerPage    int         `json:"per_page"`
+	Total      int         `json:"total"`
+	TotalPages int         `json:"total_pages"`
+}
+
+// Paginated writes items wrapped in an offset pagination envelope.
+// The total number of pages is derived from total and perPage, counting
+// a partial last page as a full page.
+func Paginated(w http.ResponseWriter, status int, items interface{}, page, perPage, total int) error {
+	totalPages := 0
+	if perPage > 0 {
+		totalPages = (total + perPage - 1) / perPage
+	}
+
+	return JSON(w, PageEnvelope{
+		Data:       items,
+		Page:       page,
+		PerPage:    perPage,
+		Total:      total,
+		TotalPages: totalPages,
+	}, status)
+}
+
+// CursorEnvelope is the JSON envelope written by Cursor.
+type CursorEnvelope struct {
+	Data       interface{} `json:"data"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
+// Cursor writes items wrapped in a cursor pagination envelope.
+// The next_cursor field is omitted when nextCursor is empty, signalling
+// the last page.
+func Cursor(w http.ResponseWriter, status int, items interface{}, nextCursor string) error {
+	return JSON(w, CursorEnvelope{Data: items, NextCursor: nextCursor}, status)
+}
@@ -0,0 +1,105 @@
+package httpx
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// maxCachedParams is the number of path parameters PathParams memoizes.
+// Lookups beyond it still work but go to the request each time.
+const maxCachedParams = 8
+
+// PathParams gives typed access to a request's path parameters, memoizing
+// each value and its integer conversion so that repeated reads of the same
+// parameter do not look it up or parse it again. It does not allocate.
+// A PathParams must not be copied after first use.
+type PathParams struct {
+	r       *http.Request
+	entries [maxCachedParams]paramEntry
+	n       int
+}
+
+// paramEntry is a memoized path parameter.
+type paramEntry struct {
+	name   string
+	value  string
+	num    int64
+	numErr error
+	parsed bool
+}
+
+// Params returns typed accessors for the path parameters of r. The result
+// can be used directly, e.g. httpx.Params(r).Int("id"), or kept to reuse
+// the memoized values; it stays on the stack unless it escapes.
+//
+// Example:
+//
+//	p := httpx.Params(r)
+//	id, err := p.Int("id")
+//	if err != nil {
+//		return httpx.BadRequest(w, err)
+//	}
+func Params(r *http.Request) *PathParams {
+	return &PathParams{r: r}
+}
+
+// entry returns the memoized entry for name, looking it up on first use.
+// It returns nil once the cache is full and name is not in it.
+func (p *PathParams) entry(name string) *paramEntry {
+	for i := 0; i < p.n; i++ {
+		if p.entries[i].name == name {
+			return &p.entries[i]
+		}
+	}
+	if p.n == len(p.entries) {
+		return nil
+	}
+	e := &p.entries[p.n]
+	p.n++
+	*e = paramEntry{name: name, value: p.r.PathValue(name)}
+	return e
+}
+
+// String returns the value of the path parameter name, or "" if the matched
+// route has no such parameter.
+func (p *PathParams) String(name string) string {
+	if e := p.entry(name); e != nil {
+		return e.value
+	}
+	return p.r.PathValue(name)
+}
+
+// Int64 returns the path parameter name parsed as a base-10 int64.
+func (p *PathParams) Int64(name string) (int64, error) {
+	e := p.entry(name)
+	if e == nil {
+		return parseParam(name, p.r.PathValue(name))
+	}
+	if !e.parsed {
+		e.num, e.numErr = parseParam(name, e.value)
+		e.parsed = true
+	}
+	return e.num, e.numErr
+}
+
+// Int returns the path parameter name parsed as a base-10 int.
+func (p *PathParams) Int(name string) (int, error) {
+	n, err := p.Int64(name)
+	if err != nil {
+		return 0, err
+	}
+	if int64(int(n)) != n {
+		return 0, fmt.Errorf("invalid path parameter %q: value out of range", name)
+	}
+	return int(n), nil
+}
+
+// parseParam parses the value of the path parameter name as an int64.
+func parseParam(name, value string) (int64, error) {
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid path parameter %q: %w", name, err)
+	}
+	return n, nil
+}
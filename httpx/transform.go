@@ -0,0 +1,65 @@
+package httpx
+
+import (
+	"context"
+	"net/http"
+)
+
+// ResponseTransformer rewrites the data of a JSON response before it is
+// encoded, e.g. to wrap every response in a common envelope. status is the
+// response's status code, so error responses can be told apart.
+type ResponseTransformer func(r *http.Request, data interface{}, status int) interface{}
+
+// transformerKey is the context key for a request-scoped ResponseTransformer.
+type transformerKey struct{}
+
+// ContextWithTransformer returns a copy of ctx carrying transformer. JSON
+// responses written by handlers served with that context are passed through
+// transformer, including error responses from JSONErrorResponder.
+func ContextWithTransformer(ctx context.Context, transformer ResponseTransformer) context.Context {
+	return context.WithValue(ctx, transformerKey{}, transformer)
+}
+
+// TransformerFromContext returns the ResponseTransformer stored in ctx, and
+// whether one was present.
+func TransformerFromContext(ctx context.Context) (ResponseTransformer, bool) {
+	transformer, ok := ctx.Value(transformerKey{}).(ResponseTransformer)
+	return transformer, ok
+}
+
+// transform applies the request's ResponseTransformer, if any, to data,
+// and then EmptyCollections if the request's context asks for it. r may be
+// nil.
+func transform(r *http.Request, data interface{}, status int) interface{} {
+	if r == nil {
+		return data
+	}
+	ctx := r.Context()
+	if transformer, ok := TransformerFromContext(ctx); ok {
+		data = transformer(r, data, status)
+	}
+	if EmptyCollectionsFromContext(ctx) {
+		data = EmptyCollections(data)
+	}
+	return data
+}
+
+// Envelope is a ResponseTransformer that wraps successful responses as
+// {"data": ..., "success": true} and error responses (status 400 and above)
+// as {"error": ..., "success": false}. The error is the "error" field of the
+// body written by JSONErrorResponder, or the whole body otherwise.
+//
+// Example:
+//
+//	router.Use(middleware.Transform(httpx.Envelope))
+func Envelope(_ *http.Request, data interface{}, status int) interface{} {
+	if status < http.StatusBadRequest {
+		return map[string]interface{}{"data": data, "success": true}
+	}
+	if body, ok := data.(map[string]string); ok && len(body) == 1 {
+		if message, ok := body["error"]; ok {
+			data = message
+		}
+	}
+	return map[string]interface{}{"error": data, "success": false}
+}
@@ -0,0 +1,134 @@
+package httpx
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+)
+
+// ResponseWriter wraps an http.ResponseWriter and records whether the
+// response has started, i.e. whether the status line and headers were sent.
+type ResponseWriter struct {
+	http.ResponseWriter
+	status    int
+	size      int
+	written   bool
+	responder ErrorResponder
+	request   *http.Request
+
+	// collecting is set while an ErrorMiddleware waits for the error of
+	// the handlers below it, which is then stored in pending.
+	collecting bool
+	pending    error
+}
+
+// NewResponseWriter wraps w in a ResponseWriter. If w is already a
+// *ResponseWriter it is returned unchanged.
+func NewResponseWriter(w http.ResponseWriter) *ResponseWriter {
+	if rw, ok := w.(*ResponseWriter); ok {
+		return rw
+	}
+	return &ResponseWriter{ResponseWriter: w}
+}
+
+// WriteHeader sends the status code once; subsequent calls are ignored.
+// Informational 1xx statuses other than 101 Switching Protocols, such as
+// 103 Early Hints, are passed through without starting the response.
+func (w *ResponseWriter) WriteHeader(statusCode int) {
+	if w.written {
+		return
+	}
+	if statusCode >= 100 && statusCode < 200 && statusCode != http.StatusSwitchingProtocols {
+		w.ResponseWriter.WriteHeader(statusCode)
+		return
+	}
+	w.status = statusCode
+	w.written = true
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write writes the body, sending an implicit 200 status first if needed.
+func (w *ResponseWriter) Write(b []byte) (int, error) {
+	if !w.written {
+		w.WriteHeader(http.StatusOK)
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.size += n
+	return n, err
+}
+
+// Flush implements http.Flusher if the underlying writer supports it.
+func (w *ResponseWriter) Flush() {
+	if !w.written {
+		w.WriteHeader(http.StatusOK)
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// ReadFrom implements io.ReaderFrom, so that io.Copy into the response can
+// use the underlying writer's ReadFrom, e.g. sendfile for files.
+func (w *ResponseWriter) ReadFrom(src io.Reader) (int64, error) {
+	if !w.written {
+		w.WriteHeader(http.StatusOK)
+	}
+	var n int64
+	var err error
+	if rf, ok := w.ResponseWriter.(io.ReaderFrom); ok {
+		n, err = rf.ReadFrom(src)
+	} else {
+		n, err = io.Copy(struct{ io.Writer }{w.ResponseWriter}, src)
+	}
+	w.size += int(n)
+	return n, err
+}
+
+// Hijack implements http.Hijacker if the underlying writer supports it.
+// A hijacked response counts as written.
+func (w *ResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(w.ResponseWriter).Hijack()
+	if err == nil {
+		w.written = true
+	}
+	return conn, rw, err
+}
+
+// Written reports whether the response has started.
+func (w *ResponseWriter) Written() bool {
+	return w.written
+}
+
+// Status returns the status code sent, or 0 if the response has not started.
+func (w *ResponseWriter) Status() int {
+	return w.status
+}
+
+// Size returns the number of body bytes written so far.
+func (w *ResponseWriter) Size() int {
+	return w.size
+}
+
+// Unwrap returns the underlying http.ResponseWriter.
+// It allows http.ResponseController to reach the original writer.
+func (w *ResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Written reports whether the response on w has already started. It walks
+// through wrapping writers that implement Unwrap until it finds one that
+// tracks this, and returns false if none does.
+func Written(w http.ResponseWriter) bool {
+	for w != nil {
+		if tw, ok := w.(interface{ Written() bool }); ok {
+			return tw.Written()
+		}
+		uw, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return false
+		}
+		w = uw.Unwrap()
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package httpx
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// IsXMLContentType reports whether contentType denotes XML, i.e. is
+// application/xml, text/xml or a structured +xml type such as
+// application/atom+xml. Parameters such as charset are ignored.
+func IsXMLContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml")
+}
+
+// DecodeXML decodes the XML request body into the provided value.
+// Requests that declare a Content-Type other than XML are rejected with a
+// *StatusError with 415 Unsupported Media Type; a missing Content-Type is
+// accepted.
+func DecodeXML(r *http.Request, v interface{}) error {
+	if r.Body == nil {
+		return errors.New("request body is empty")
+	}
+	if ct := r.Header.Get("Content-Type"); ct != "" && !IsXMLContentType(ct) {
+		return unsupportedMediaType(ct)
+	}
+	defer r.Body.Close()
+
+	if err := xml.NewDecoder(r.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode XML: %w", err)
+	}
+
+	return nil
+}
+
+// XML sets the Content-Type to "application/xml", sets the provided status
+// code, and encodes the data as an XML document with an XML declaration.
+// As with JSON, the data is encoded before anything is written.
+func XML(w http.ResponseWriter, data interface{}, statusCode int) error {
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	buf.WriteString(xml.Header)
+	if err := xml.NewEncoder(buf).Encode(data); err != nil {
+		return fmt.Errorf("failed to encode XML: %w", err)
+	}
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(statusCode)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
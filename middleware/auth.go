@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/vibe-go/vibe/httpx"
+)
+
+// APIKeyQueryParam is the query parameter APIKey reads the key from when the
+// request does not carry the key header.
+const APIKeyQueryParam = "api_key"
+
+// APIKey returns a middleware that authenticates requests by an API key read
+// from the given header, or from the api_key query parameter if the header
+// is absent. validate reports whether the key is valid, and may return a
+// context holding values for the handler, such as the account the key
+// belongs to; its values are added to the request's context. Requests with a
+// missing or invalid key are rejected with 401 Unauthorized.
+//
+// Example:
+//
+//	router.Use(middleware.APIKey("X-API-Key", func(key string) (bool, context.Context) {
+//		account, ok := accounts.ByKey(key)
+//		if !ok {
+//			return false, nil
+//		}
+//		return true, context.WithValue(context.Background(), accountKey{}, account)
+//	}))
+func APIKey(header string, validate func(key string) (bool, context.Context)) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return httpx.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			key := r.Header.Get(header)
+			if key == "" {
+				key = r.URL.Query().Get(APIKeyQueryParam)
+			}
+			if key == "" {
+				return httpx.Error(w, errors.New("missing API key"), http.StatusUnauthorized)
+			}
+
+			ok, values := validate(key)
+			if !ok {
+				return httpx.Error(w, errors.New("invalid API key"), http.StatusUnauthorized)
+			}
+			if values != nil {
+				r = r.WithContext(valuesContext{Context: r.Context(), values: values})
+			}
+
+			next.ServeHTTP(w, r)
+			return nil
+		})
+	}
+}
+
+// scopesKey is the context key for the scopes granted to a request.
+type scopesKey struct{}
+
+// ContextWithScopes returns a copy of ctx carrying the scopes or roles
+// granted to the request. Authentication middleware sets them, e.g. from a
+// token's claims or in the context returned by an APIKey validator, for
+// RequireScope to check.
+func ContextWithScopes(ctx context.Context, scopes ...string) context.Context {
+	return context.WithValue(ctx, scopesKey{}, scopes)
+}
+
+// ScopesFrom returns the scopes stored in ctx by ContextWithScopes.
+func ScopesFrom(ctx context.Context) []string {
+	scopes, _ := ctx.Value(scopesKey{}).([]string)
+	return scopes
+}
+
+// RequireScope returns a middleware that rejects requests not granted all of
+// the given scopes with 403 Forbidden. It only authorizes: it must run after
+// the middleware that authenticated the request and stored its scopes with
+// ContextWithScopes.
+//
+// Example:
+//
+//	admin := router.Group("/admin", auth, middleware.RequireScope("admin"))
+//	admin.Delete("/users/{id}", deleteUser, middleware.RequireScope("users:write"))
+func RequireScope(scopes ...string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return httpx.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			granted := ScopesFrom(r.Context())
+			for _, scope := range scopes {
+				if !slices.Contains(granted, scope) {
+					return httpx.Error(w, fmt.Errorf("missing required scope %q", scope), http.StatusForbidden)
+				}
+			}
+
+			next.ServeHTTP(w, r)
+			return nil
+		})
+	}
+}
+
+// valuesContext is a request context that also carries the values of
+// another context, which take precedence. Deadline and cancellation still
+// come from the request context.
+type valuesContext struct {
+	context.Context
+	values context.Context
+}
+
+// Value returns the value for key from values, or from the request context.
+func (c valuesContext) Value(key interface{}) interface{} {
+	if v := c.values.Value(key); v != nil {
+		return v
+	}
+	return c.Context.Value(key)
+}
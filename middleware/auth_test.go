@@ -0,0 +1,140 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/vibe-go/vibe/middleware"
+)
+
+// accountKey is the context key for the account in API key tests.
+type accountKey struct{}
+
+func TestAPIKey(t *testing.T) {
+	var account string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		account, _ = r.Context().Value(accountKey{}).(string)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	wrapped := middleware.APIKey("X-API-Key", func(key string) (bool, context.Context) {
+		if key != "secret" {
+			return false, nil
+		}
+		return true, context.WithValue(context.Background(), accountKey{}, "acme")
+	})(handler)
+
+	tests := []struct {
+		name    string
+		target  string
+		header  string
+		status  int
+		account string
+	}{
+		{"ValidHeader", "/", "secret", http.StatusOK, "acme"},
+		{"ValidQuery", "/?api_key=secret", "", http.StatusOK, "acme"},
+		{"Invalid", "/", "wrong", http.StatusUnauthorized, ""},
+		{"Missing", "/", "", http.StatusUnauthorized, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			account = ""
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.header != "" {
+				req.Header.Set("X-API-Key", tt.header)
+			}
+			w := httptest.NewRecorder()
+
+			wrapped.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Errorf("Expected status code %d, got %d", tt.status, w.Code)
+			}
+			if account != tt.account {
+				t.Errorf("Expected account '%s', got '%s'", tt.account, account)
+			}
+		})
+	}
+
+	// Test case: the request context keeps its own values and cancellation
+	t.Run("KeepsRequestContext", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.WithValue(context.Background(), requestKey{}, "req"))
+		var seen string
+		var canceled bool
+		wrapped := middleware.APIKey("X-API-Key", func(string) (bool, context.Context) {
+			return true, context.WithValue(context.Background(), accountKey{}, "acme")
+		})(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			seen, _ = r.Context().Value(requestKey{}).(string)
+			cancel()
+			canceled = r.Context().Err() != nil
+		}))
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+		req.Header.Set("X-API-Key", "any")
+		wrapped.ServeHTTP(httptest.NewRecorder(), req)
+
+		if seen != "req" {
+			t.Errorf("Expected request context value 'req', got '%s'", seen)
+		}
+		if !canceled {
+			t.Error("Expected cancellation of the request context to propagate")
+		}
+	})
+}
+
+// requestKey is a context key set on the incoming request in tests.
+type requestKey struct{}
+
+func TestRequireScope(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	// auth grants scopes by API key.
+	auth := middleware.APIKey("X-API-Key", func(key string) (bool, context.Context) {
+		switch key {
+		case "writer":
+			return true, middleware.ContextWithScopes(context.Background(), "posts:read", "posts:write")
+		case "reader":
+			return true, middleware.ContextWithScopes(context.Background(), "posts:read")
+		}
+		return false, nil
+	})
+	wrapped := auth(middleware.RequireScope("posts:read", "posts:write")(handler))
+
+	tests := []struct {
+		name   string
+		key    string
+		status int
+	}{
+		{"AllScopes", "writer", http.StatusOK},
+		{"MissingScope", "reader", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/posts", nil)
+			req.Header.Set("X-API-Key", tt.key)
+			w := httptest.NewRecorder()
+
+			wrapped.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Errorf("Expected status code %d, got %d", tt.status, w.Code)
+			}
+		})
+	}
+
+	// Test case: a request without scopes is forbidden
+	t.Run("NoScopes", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		middleware.RequireScope("posts:read")(handler).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		if w.Code != http.StatusForbidden {
+			t.Errorf("Expected status code %d, got %d", http.StatusForbidden, w.Code)
+		}
+	})
+}
@@ -0,0 +1,171 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/vibe-go/vibe/httpx"
+)
+
+// rawBodyKey is the context key for the buffered request body.
+type rawBodyKey struct{}
+
+// BufferBody returns a middleware that reads the request body into memory,
+// up to maxBytes, and replaces r.Body with a reader over the buffered bytes.
+// The raw bytes remain available through RawBody, so middleware such as
+// signature verification can inspect the body without consuming it for the
+// handler. Bodies larger than maxBytes are rejected with 413 Request Entity
+// Too Large.
+//
+// Example:
+//
+//	webhooks := router.Group("/webhooks", middleware.BufferBody(1<<20), verifySignature)
+func BufferBody(maxBytes int64) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return httpx.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			var body []byte
+			if r.Body != nil {
+				var err error
+				body, err = io.ReadAll(io.LimitReader(r.Body, maxBytes+1))
+				r.Body.Close()
+				if err != nil {
+					return httpx.BadRequest(w, fmt.Errorf("failed to read request body: %w", err))
+				}
+				if int64(len(body)) > maxBytes {
+					return httpx.Error(w, fmt.Errorf("request body exceeds %d bytes", maxBytes),
+						http.StatusRequestEntityTooLarge)
+				}
+			}
+
+			r = r.WithContext(context.WithValue(r.Context(), rawBodyKey{}, body))
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			r.GetBody = func() (io.ReadCloser, error) {
+				return io.NopCloser(bytes.NewReader(body)), nil
+			}
+
+			next.ServeHTTP(w, r)
+			return nil
+		})
+	}
+}
+
+// RawBody returns the request body buffered by BufferBody, and whether the
+// middleware ran for the request. The returned slice must not be modified.
+func RawBody(r *http.Request) ([]byte, bool) {
+	body, ok := r.Context().Value(rawBodyKey{}).([]byte)
+	return body, ok
+}
+
+// DecompressOption configures the DecompressRequest middleware.
+type DecompressOption func(*decompressConfig)
+
+// decompressConfig holds the configuration for the DecompressRequest
+// middleware.
+type decompressConfig struct {
+	maxBytes int64
+}
+
+// WithMaxDecompressedBytes limits the size of a decompressed request body.
+// Reading past maxBytes fails with a *httpx.StatusError with 413 Request
+// Entity Too Large, which guards against small bodies that inflate to huge
+// ones. By default the decompressed size is not limited.
+func WithMaxDecompressedBytes(maxBytes int64) DecompressOption {
+	return func(c *decompressConfig) {
+		c.maxBytes = maxBytes
+	}
+}
+
+// DecompressRequest returns a middleware that transparently decompresses
+// request bodies sent with "Content-Encoding: gzip", so that handlers and
+// decoders downstream read plain bytes. The Content-Encoding and
+// Content-Length headers are removed, since they no longer describe the
+// body. A body that is not valid gzip is rejected with 400 Bad Request;
+// corrupt data found while the handler reads the body is reported as a
+// *httpx.StatusError with 400 Bad Request.
+//
+// Example:
+//
+//	router.Use(middleware.DecompressRequest(middleware.WithMaxDecompressedBytes(10 << 20)))
+func DecompressRequest(options ...DecompressOption) func(next http.Handler) http.Handler {
+	cfg := &decompressConfig{}
+	for _, option := range options {
+		option(cfg)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return httpx.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			encoding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding")))
+			if r.Body == nil || (encoding != "gzip" && encoding != "x-gzip") {
+				next.ServeHTTP(w, r)
+				return nil
+			}
+
+			zr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				return httpx.BadRequest(w, fmt.Errorf("malformed gzip request body: %w", err))
+			}
+
+			r = r.Clone(r.Context())
+			r.Body = &gzipBody{Reader: zr, body: r.Body, maxBytes: cfg.maxBytes}
+			r.Header.Del("Content-Encoding")
+			r.Header.Del("Content-Length")
+			r.ContentLength = -1
+			r.GetBody = nil
+
+			next.ServeHTTP(w, r)
+			return nil
+		})
+	}
+}
+
+// gzipBody is a request body decompressed by DecompressRequest.
+type gzipBody struct {
+	*gzip.Reader
+	body     io.ReadCloser
+	maxBytes int64
+	read     int64
+}
+
+// Read reads decompressed bytes, failing with a 413 *httpx.StatusError past
+// the size limit and with a 400 *httpx.StatusError on corrupt gzip data.
+func (b *gzipBody) Read(p []byte) (int, error) {
+	if b.maxBytes > 0 && b.read > b.maxBytes {
+		return 0, b.tooLarge()
+	}
+	n, err := b.Reader.Read(p)
+	b.read += int64(n)
+	if b.maxBytes > 0 && b.read > b.maxBytes {
+		return max(n-int(b.read-b.maxBytes), 0), b.tooLarge()
+	}
+	if isCorruptGzip(err) {
+		err = httpx.NewStatusError(http.StatusBadRequest, fmt.Errorf("malformed gzip request body: %w", err))
+	}
+	return n, err
+}
+
+// tooLarge returns the error reported once the body exceeds the limit.
+func (b *gzipBody) tooLarge() error {
+	return httpx.NewStatusError(http.StatusRequestEntityTooLarge,
+		fmt.Errorf("decompressed request body exceeds %d bytes", b.maxBytes))
+}
+
+// isCorruptGzip reports whether err, returned by a gzip.Reader, means the
+// compressed data is invalid rather than that the underlying read failed.
+func isCorruptGzip(err error) bool {
+	var corrupt flate.CorruptInputError
+	return errors.Is(err, gzip.ErrChecksum) || errors.Is(err, gzip.ErrHeader) ||
+		errors.Is(err, io.ErrUnexpectedEOF) || errors.As(err, &corrupt)
+}
+
+// Close closes both the gzip reader and the original body.
+func (b *gzipBody) Close() error {
+	b.Reader.Close()
+	return b.body.Close()
+}
@@ -0,0 +1,203 @@
+package middleware_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/vibe-go/vibe/httpx"
+	"github.com/vibe-go/vibe/middleware"
+)
+
+func TestBufferBody(t *testing.T) {
+	const payload = `{"event":"paid","amount":42}`
+
+	// Test case: middleware and handler both see the full body
+	t.Run("ReReadable", func(t *testing.T) {
+		var seenByMiddleware string
+		inspect := func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				raw, _ := middleware.RawBody(r)
+				seenByMiddleware = string(raw)
+				next.ServeHTTP(w, r)
+			})
+		}
+
+		var decoded struct {
+			Event  string `json:"event"`
+			Amount int    `json:"amount"`
+		}
+		handler := httpx.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if err := httpx.DecodeJSON(r, &decoded); err != nil {
+				return httpx.BadRequest(w, err)
+			}
+			w.WriteHeader(http.StatusOK)
+			return nil
+		})
+
+		wrapped := middleware.BufferBody(1024)(inspect(handler))
+
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(payload))
+		w := httptest.NewRecorder()
+
+		wrapped.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+		}
+
+		if seenByMiddleware != payload {
+			t.Errorf("Expected middleware to see %s, got %s", payload, seenByMiddleware)
+		}
+
+		if decoded.Event != "paid" || decoded.Amount != 42 {
+			t.Errorf("Expected handler to decode the full body, got %+v", decoded)
+		}
+	})
+
+	// Test case: body over the limit is rejected
+	t.Run("TooLarge", func(t *testing.T) {
+		handler := httpx.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) error {
+			w.WriteHeader(http.StatusOK)
+			return nil
+		})
+
+		wrapped := middleware.BufferBody(8)(handler)
+
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(payload))
+		w := httptest.NewRecorder()
+
+		wrapped.ServeHTTP(w, req)
+
+		if w.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("Expected status code %d, got %d: %s", http.StatusRequestEntityTooLarge, w.Code, w.Body.String())
+		}
+	})
+}
+
+func TestDecompressRequest(t *testing.T) {
+	type payload struct {
+		Event  string `json:"event"`
+		Amount int    `json:"amount"`
+	}
+
+	handler := httpx.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		var p payload
+		if err := httpx.DecodeJSON(r, &p); err != nil {
+			return httpx.BadRequest(w, err)
+		}
+		return httpx.JSON(w, p, http.StatusOK)
+	})
+
+	wrapped := middleware.DecompressRequest()(handler)
+
+	// Test case: gzip-compressed JSON is decoded downstream
+	t.Run("Gzip", func(t *testing.T) {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		zw.Write([]byte(`{"event":"paid","amount":42}`))
+		zw.Close()
+
+		req := httptest.NewRequest(http.MethodPost, "/", &buf)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Content-Encoding", "gzip")
+		w := httptest.NewRecorder()
+
+		wrapped.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+
+		expected := `{"event":"paid","amount":42}`
+		if strings.TrimSpace(w.Body.String()) != expected {
+			t.Errorf("Expected body %s, got %s", expected, w.Body.String())
+		}
+	})
+
+	// Test case: uncompressed bodies pass through
+	t.Run("Plain", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"event":"paid"}`))
+		w := httptest.NewRecorder()
+
+		wrapped.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+		}
+	})
+
+	// Test case: a body that is not gzip is rejected
+	t.Run("Malformed", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"event":"paid"}`))
+		req.Header.Set("Content-Encoding", "gzip")
+		w := httptest.NewRecorder()
+
+		wrapped.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
+		}
+		if !strings.Contains(w.Body.String(), "malformed gzip") {
+			t.Errorf("Expected malformed gzip error, got %s", w.Body.String())
+		}
+	})
+
+	// Test case: a body that inflates past the limit is rejected
+	t.Run("MaxDecompressedBytes", func(t *testing.T) {
+		limited := middleware.DecompressRequest(middleware.WithMaxDecompressedBytes(16))(
+			httpx.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+				var p payload
+				if err := httpx.DecodeJSON(r, &p); err != nil {
+					return err
+				}
+				return httpx.JSON(w, p, http.StatusOK)
+			}))
+
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		zw.Write([]byte(`{"event":"paid","amount":42}`))
+		zw.Close()
+
+		req := httptest.NewRequest(http.MethodPost, "/", &buf)
+		req.Header.Set("Content-Encoding", "gzip")
+		w := httptest.NewRecorder()
+
+		limited.ServeHTTP(w, req)
+
+		if w.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("Expected status code %d, got %d: %s", http.StatusRequestEntityTooLarge, w.Code, w.Body.String())
+		}
+	})
+
+	// Test case: corrupt data after a valid header is a 400 StatusError
+	t.Run("CorruptStream", func(t *testing.T) {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		zw.Write([]byte(`{"event":"paid","amount":42}`))
+		zw.Close()
+		truncated := buf.Bytes()[:buf.Len()-6]
+
+		var readErr error
+		reader := middleware.DecompressRequest()(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			_, readErr = io.ReadAll(r.Body)
+		}))
+
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(truncated))
+		req.Header.Set("Content-Encoding", "gzip")
+		reader.ServeHTTP(httptest.NewRecorder(), req)
+
+		var statusErr *httpx.StatusError
+		if !errors.As(readErr, &statusErr) {
+			t.Fatalf("Expected *httpx.StatusError, got %v", readErr)
+		}
+		if statusErr.Status != http.StatusBadRequest {
+			t.Errorf("Expected status %d, got %d", http.StatusBadRequest, statusErr.Status)
+		}
+	})
+}
@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"context"
+	"net/http"
+	"strings"
+)
+
+// compressKey is the context key for the compression state of a request.
+type compressKey struct{}
+
+// compressState lets middleware after Compress opt the response out of
+// compression.
+type compressState struct {
+	disabled bool
+}
+
+// Compress returns a middleware that gzip-compresses responses for clients
+// that send "Accept-Encoding: gzip". Responses that already carry a
+// Content-Encoding, partial content (206) responses to range requests,
+// whose Content-Range refers to the uncompressed bytes, and routes marked
+// with NoCompress are sent as is.
+//
+// Example:
+//
+//	router.Use(middleware.Compress())
+func Compress() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			AddVary(w.Header(), "Accept-Encoding")
+			if !AcceptsGzip(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			state := &compressState{}
+			cw := &compressWriter{ResponseWriter: w, state: state}
+			defer cw.close()
+
+			next.ServeHTTP(cw, r.WithContext(context.WithValue(r.Context(), compressKey{}, state)))
+		})
+	}
+}
+
+// NoCompress returns a middleware that keeps Compress from compressing the
+// response, e.g. for routes serving files that are already compressed. It
+// has no effect without Compress.
+//
+// Example:
+//
+//	router.Get("/downloads/{name}", download, middleware.NoCompress())
+func NoCompress() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if state, ok := r.Context().Value(compressKey{}).(*compressState); ok {
+				state.disabled = true
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// AcceptsGzip reports whether the request's Accept-Encoding header accepts
+// gzip-encoded responses.
+func AcceptsGzip(r *http.Request) bool {
+	for _, mr := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(mr), ";")
+		if strings.EqualFold(strings.TrimSpace(coding), "gzip") {
+			return strings.ReplaceAll(params, " ", "") != "q=0"
+		}
+	}
+	return false
+}
+
+// compressWriter decides whether to compress when the response starts, and
+// then writes the body through a gzip.Writer if it does.
+type compressWriter struct {
+	http.ResponseWriter
+	state   *compressState
+	gz      *gzip.Writer
+	started bool
+}
+
+// start decides whether to compress the response with the given status.
+func (cw *compressWriter) start(status int) {
+	cw.started = true
+	h := cw.Header()
+	if cw.state.disabled || h.Get("Content-Encoding") != "" || h.Get("Content-Range") != "" ||
+		status == http.StatusNoContent || status == http.StatusNotModified ||
+		status == http.StatusPartialContent {
+		return
+	}
+	h.Set("Content-Encoding", "gzip")
+	h.Del("Content-Length")
+	cw.gz = gzip.NewWriter(cw.ResponseWriter)
+}
+
+// WriteHeader starts the response with statusCode.
+func (cw *compressWriter) WriteHeader(statusCode int) {
+	if !cw.started {
+		cw.start(statusCode)
+	}
+	cw.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write writes b, compressed if the response is being compressed.
+func (cw *compressWriter) Write(b []byte) (int, error) {
+	if !cw.started {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if cw.gz != nil {
+		return cw.gz.Write(b)
+	}
+	return cw.ResponseWriter.Write(b)
+}
+
+// Flush flushes compressed data and the underlying writer. Flushing before
+// the first write, as streaming handlers often do, starts the response.
+func (cw *compressWriter) Flush() {
+	if !cw.started {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if cw.gz != nil {
+		cw.gz.Flush()
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying http.ResponseWriter.
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// close finishes the gzip stream, if any. The response has started by
+// then, so a write error cannot be reported to the client.
+func (cw *compressWriter) close() {
+	if cw.gz != nil {
+		_ = cw.gz.Close()
+	}
+}
@@ -0,0 +1,141 @@
+package middleware_test
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vibe-go/vibe/middleware"
+)
+
+func TestCompress(t *testing.T) {
+	body := strings.Repeat("hello world ", 100)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(body))
+	})
+
+	// Test case: gzip-accepting clients get a compressed body
+	t.Run("Compressed", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept-Encoding", "gzip, deflate")
+		w := httptest.NewRecorder()
+
+		middleware.Compress()(handler).ServeHTTP(w, req)
+
+		if w.Header().Get("Content-Encoding") != "gzip" {
+			t.Fatalf("Expected Content-Encoding 'gzip', got '%s'", w.Header().Get("Content-Encoding"))
+		}
+		zr, err := gzip.NewReader(w.Body)
+		if err != nil {
+			t.Fatalf("Expected a gzip body, got error: %v", err)
+		}
+		decoded, _ := io.ReadAll(zr)
+		if string(decoded) != body {
+			t.Errorf("Expected decompressed body to match, got %d bytes", len(decoded))
+		}
+	})
+
+	// Test case: other clients get the plain body
+	t.Run("NotAccepted", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		w := httptest.NewRecorder()
+
+		middleware.Compress()(handler).ServeHTTP(w, req)
+
+		if w.Header().Get("Content-Encoding") != "" || w.Body.String() != body {
+			t.Errorf("Expected an uncompressed body, got Content-Encoding '%s'", w.Header().Get("Content-Encoding"))
+		}
+	})
+
+	// Test case: a route flagged with NoCompress is sent as is
+	t.Run("NoCompress", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		w := httptest.NewRecorder()
+
+		middleware.Compress()(middleware.NoCompress()(handler)).ServeHTTP(w, req)
+
+		if w.Header().Get("Content-Encoding") != "" {
+			t.Errorf("Expected no Content-Encoding, got '%s'", w.Header().Get("Content-Encoding"))
+		}
+		if w.Body.String() != body {
+			t.Errorf("Expected an uncompressed body, got %d bytes", w.Body.Len())
+		}
+	})
+	// Test case: range responses are sent as is, since Content-Range
+	// describes the uncompressed bytes
+	t.Run("PartialContent", func(t *testing.T) {
+		content := strings.NewReader(body)
+		ranged := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.ServeContent(w, r, "body.txt", time.Time{}, content)
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		req.Header.Set("Range", "bytes=0-4")
+		w := httptest.NewRecorder()
+
+		middleware.Compress()(ranged).ServeHTTP(w, req)
+
+		if w.Code != http.StatusPartialContent {
+			t.Fatalf("Expected status code %d, got %d", http.StatusPartialContent, w.Code)
+		}
+		if w.Header().Get("Content-Encoding") != "" {
+			t.Errorf("Expected no Content-Encoding, got '%s'", w.Header().Get("Content-Encoding"))
+		}
+		if w.Body.String() != "hello" {
+			t.Errorf("Expected body 'hello', got '%s'", w.Body.String())
+		}
+	})
+
+	// Test case: flushing before the first write still sends the encoding
+	t.Run("FlushBeforeWrite", func(t *testing.T) {
+		streaming := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.(http.Flusher).Flush()
+			w.Write([]byte(body))
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		w := httptest.NewRecorder()
+
+		middleware.Compress()(streaming).ServeHTTP(w, req)
+
+		resp := w.Result()
+		if got := resp.Header.Get("Content-Encoding"); got != "gzip" {
+			t.Fatalf("Expected Content-Encoding 'gzip', got '%s'", got)
+		}
+		zr, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			t.Fatalf("Expected a gzip body, got error: %v", err)
+		}
+		decoded, _ := io.ReadAll(zr)
+		if string(decoded) != body {
+			t.Errorf("Expected decompressed body to match, got %d bytes", len(decoded))
+		}
+	})
+
+	// Test case: Vary is not repeated when the handler also sets it
+	t.Run("VaryOnce", func(t *testing.T) {
+		varying := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			middleware.AddVary(w.Header(), "Accept-Encoding")
+			w.Write([]byte(body))
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		w := httptest.NewRecorder()
+
+		middleware.Compress()(varying).ServeHTTP(w, req)
+
+		if got := w.Header().Values("Vary"); len(got) != 1 || got[0] != "Accept-Encoding" {
+			t.Errorf("Expected Vary [Accept-Encoding], got %v", got)
+		}
+	})
+}
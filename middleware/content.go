@@ -0,0 +1,187 @@
+package middleware
+
+import (
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/vibe-go/vibe/httpx"
+)
+
+// ContentOption configures the EnforceJSON middleware.
+type ContentOption func(*contentConfig)
+
+// contentConfig holds the configuration for the EnforceJSON middleware.
+type contentConfig struct {
+	strict bool
+}
+
+// WithStrictJSON makes EnforceJSON replace responses with a non-JSON
+// Content-Type with a 500 error, instead of only logging the mismatch.
+func WithStrictJSON() ContentOption {
+	return func(c *contentConfig) {
+		c.strict = true
+	}
+}
+
+// EnforceJSON returns a middleware that guarantees responses are sent with a
+// JSON Content-Type. Responses without a Content-Type default to
+// "application/json". When a handler sets a different Content-Type, the
+// mismatch is logged, and with WithStrictJSON the response is also replaced
+// with a 500 error.
+//
+// Example:
+//
+//	api.Use(middleware.EnforceJSON(nil, middleware.WithStrictJSON()))
+func EnforceJSON(logger *log.Logger, options ...ContentOption) func(next http.Handler) http.Handler {
+	cfg := &contentConfig{}
+	for _, option := range options {
+		option(cfg)
+	}
+	if logger == nil {
+		logger = log.New(log.Writer(), "[json] ", log.LstdFlags)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return httpx.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			next.ServeHTTP(&jsonWriter{
+				ResponseWriter: w,
+				logger:         logger,
+				strict:         cfg.strict,
+				path:           r.URL.Path,
+			}, r)
+			return nil
+		})
+	}
+}
+
+// jsonWriter checks the Content-Type just before the response headers are sent.
+type jsonWriter struct {
+	http.ResponseWriter
+	logger      *log.Logger
+	strict      bool
+	path        string
+	wroteHeader bool
+	rejected    bool
+}
+
+// WriteHeader applies or verifies the JSON Content-Type and writes the status code.
+func (w *jsonWriter) WriteHeader(statusCode int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+
+	contentType := w.Header().Get("Content-Type")
+	switch {
+	case contentType == "":
+		w.Header().Set("Content-Type", "application/json")
+	case !httpx.IsJSONContentType(contentType):
+		w.logger.Printf("non-JSON Content-Type %q written for %s", contentType, w.path)
+		if w.strict {
+			w.rejected = true
+			err := httpx.InternalError(w.ResponseWriter,
+				fmt.Errorf("handler set non-JSON Content-Type %q", contentType))
+			if err != nil {
+				w.logger.Printf("failed to write error response: %v", err)
+			}
+			return
+		}
+	}
+
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write writes the body, discarding it if the response was rejected.
+func (w *jsonWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.rejected {
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush sends the headers, checking the Content-Type first, and flushes the
+// underlying writer unless the response was rejected.
+func (w *jsonWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.rejected {
+		return
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying http.ResponseWriter.
+func (w *jsonWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// RequireContentType returns a middleware that rejects POST, PUT and PATCH
+// requests with a body whose Content-Type is not one of types, responding
+// with 415 Unsupported Media Type. Media type parameters such as charset
+// are ignored when comparing.
+//
+// Example:
+//
+//	api.Use(middleware.RequireContentType("application/json"))
+func RequireContentType(types ...string) func(next http.Handler) http.Handler {
+	allowed := make(map[string]struct{}, len(types))
+	for _, t := range types {
+		allowed[strings.ToLower(strings.TrimSpace(t))] = struct{}{}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return httpx.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			switch r.Method {
+			case http.MethodPost, http.MethodPut, http.MethodPatch:
+			default:
+				next.ServeHTTP(w, r)
+				return nil
+			}
+
+			if r.ContentLength == 0 {
+				next.ServeHTTP(w, r)
+				return nil
+			}
+
+			mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			if _, ok := allowed[mediaType]; err != nil || !ok {
+				return httpx.Error(w, fmt.Errorf("unsupported Content-Type %q", r.Header.Get("Content-Type")),
+					http.StatusUnsupportedMediaType)
+			}
+
+			next.ServeHTTP(w, r)
+			return nil
+		})
+	}
+}
+
+// RequireAccept returns a middleware that responds with 406 Not Acceptable
+// when the request's Accept header admits none of the producible types.
+// Requests without an Accept header are allowed through, as are wildcard
+// ranges such as "*/*" and "application/*".
+//
+// Example:
+//
+//	api.Use(middleware.RequireAccept("application/json"))
+func RequireAccept(types ...string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return httpx.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if httpx.NegotiateContentType(r, types) == "" {
+				return httpx.Error(w, fmt.Errorf("cannot produce a response matching Accept %q",
+					r.Header.Get("Accept")), http.StatusNotAcceptable)
+			}
+
+			next.ServeHTTP(w, r)
+			return nil
+		})
+	}
+}
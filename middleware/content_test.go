@@ -0,0 +1,190 @@
+package middleware_test
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/vibe-go/vibe/httpx"
+	"github.com/vibe-go/vibe/middleware"
+)
+
+func TestEnforceJSON(t *testing.T) {
+	// Test case: no Content-Type set by handler
+	t.Run("DefaultsToJSON", func(t *testing.T) {
+		handler := httpx.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) error {
+			w.Write([]byte(`{"message":"OK"}`))
+			return nil
+		})
+
+		wrapped := middleware.EnforceJSON(nil, middleware.WithStrictJSON())(handler)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		w := httptest.NewRecorder()
+
+		wrapped.ServeHTTP(w, req)
+
+		resp := w.Result()
+		if resp.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Expected Content-Type 'application/json', got '%s'", resp.Header.Get("Content-Type"))
+		}
+	})
+
+	// Test case: non-JSON Content-Type in lenient mode
+	t.Run("LenientLogsMismatch", func(t *testing.T) {
+		handler := httpx.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) error {
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<p>hi</p>"))
+			return nil
+		})
+
+		var buf bytes.Buffer
+		wrapped := middleware.EnforceJSON(log.New(&buf, "", 0))(handler)
+
+		req := httptest.NewRequest(http.MethodGet, "/page", nil)
+		w := httptest.NewRecorder()
+
+		wrapped.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK || w.Body.String() != "<p>hi</p>" {
+			t.Errorf("Expected response to pass through, got %d %s", w.Code, w.Body.String())
+		}
+
+		if !strings.Contains(buf.String(), "text/html") {
+			t.Errorf("Expected log to mention Content-Type, got: %s", buf.String())
+		}
+	})
+
+	// Test case: non-JSON Content-Type in strict mode
+	t.Run("StrictRejectsMismatch", func(t *testing.T) {
+		handler := httpx.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) error {
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte("<p>hi</p>"))
+			return nil
+		})
+
+		wrapped := middleware.EnforceJSON(log.New(&bytes.Buffer{}, "", 0), middleware.WithStrictJSON())(handler)
+
+		req := httptest.NewRequest(http.MethodGet, "/page", nil)
+		w := httptest.NewRecorder()
+
+		wrapped.ServeHTTP(w, req)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("Expected status code %d, got %d", http.StatusInternalServerError, w.Code)
+		}
+
+		if strings.Contains(w.Body.String(), "<p>") {
+			t.Errorf("Expected handler body to be discarded, got %s", w.Body.String())
+		}
+	})
+
+	// Test case: the wrapper keeps the response flushable
+	t.Run("Flush", func(t *testing.T) {
+		handler := httpx.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) error {
+			f, ok := w.(http.Flusher)
+			if !ok {
+				t.Fatal("Expected the ResponseWriter to implement http.Flusher")
+			}
+			w.Write([]byte(`{"status":`))
+			f.Flush()
+			return nil
+		})
+
+		wrapped := middleware.EnforceJSON(log.New(&bytes.Buffer{}, "", 0))(handler)
+
+		req := httptest.NewRequest(http.MethodGet, "/stream", nil)
+		w := httptest.NewRecorder()
+
+		wrapped.ServeHTTP(w, req)
+
+		if !w.Flushed {
+			t.Error("Expected the response to be flushed")
+		}
+		if w.Header().Get("Content-Type") != "application/json" {
+			t.Errorf("Expected Content-Type 'application/json', got '%s'", w.Header().Get("Content-Type"))
+		}
+	})
+}
+
+func TestRequireContentType(t *testing.T) {
+	handler := httpx.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) error {
+		w.WriteHeader(http.StatusOK)
+		return nil
+	})
+
+	wrapped := middleware.RequireContentType("application/json")(handler)
+
+	tests := []struct {
+		name        string
+		method      string
+		contentType string
+		body        string
+		expected    int
+	}{
+		{"JSON", http.MethodPost, "application/json", `{}`, http.StatusOK},
+		{"JSONWithCharset", http.MethodPut, "application/json; charset=utf-8", `{}`, http.StatusOK},
+		{"PlainText", http.MethodPost, "text/plain", "hello", http.StatusUnsupportedMediaType},
+		{"Missing", http.MethodPatch, "", "hello", http.StatusUnsupportedMediaType},
+		{"EmptyBody", http.MethodPost, "", "", http.StatusOK},
+		{"GetIgnored", http.MethodGet, "text/plain", "", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			w := httptest.NewRecorder()
+
+			wrapped.ServeHTTP(w, req)
+
+			if w.Code != tt.expected {
+				t.Errorf("Expected status code %d, got %d", tt.expected, w.Code)
+			}
+		})
+	}
+}
+
+func TestRequireAccept(t *testing.T) {
+	handler := httpx.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) error {
+		w.WriteHeader(http.StatusOK)
+		return nil
+	})
+
+	wrapped := middleware.RequireAccept("application/json")(handler)
+
+	tests := []struct {
+		name     string
+		accept   string
+		expected int
+	}{
+		{"Missing", "", http.StatusOK},
+		{"Exact", "application/json", http.StatusOK},
+		{"Wildcard", "*/*", http.StatusOK},
+		{"TypeWildcard", "application/*", http.StatusOK},
+		{"Browser", "text/html,application/xhtml+xml,*/*;q=0.8", http.StatusOK},
+		{"Mismatch", "application/xml", http.StatusNotAcceptable},
+		{"ZeroQuality", "application/json;q=0, text/html", http.StatusNotAcceptable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+
+			wrapped.ServeHTTP(w, req)
+
+			if w.Code != tt.expected {
+				t.Errorf("Expected status code %d, got %d", tt.expected, w.Code)
+			}
+		})
+	}
+}
@@ -3,9 +3,12 @@ package cors
 
 import (
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/vibe-go/vibe/httpx"
+	"github.com/vibe-go/vibe/middleware"
 )
 
 // DefaultMaxAge is the default max age for CORS preflight requests (24 hours).
@@ -14,9 +17,11 @@ const DefaultMaxAge = 86400
 // Config holds the configuration for CORS middleware.
 type Config struct {
 	allowOrigin      string
+	allowOrigins     []string // set when allowOrigin lists several origins
 	allowMethods     string
 	allowHeaders     string
 	allowCredentials bool
+	allowPrivateNet  bool
 	maxAge           int
 }
 
@@ -24,9 +29,24 @@ type Config struct {
 type Option func(*Config)
 
 // WithAllowOrigin sets the Access-Control-Allow-Origin header.
+// A comma-separated list of origins is matched exactly against the request's
+// Origin header: a listed origin is echoed back, and other origins get no
+// Access-Control-Allow-Origin header at all.
+//
+// Example:
+//
+//	cors.WithAllowOrigin("https://app.example.com, https://admin.example.com")
 func WithAllowOrigin(origin string) Option {
 	return func(c *Config) {
 		c.allowOrigin = origin
+		c.allowOrigins = nil
+		if strings.Contains(origin, ",") {
+			for _, o := range strings.Split(origin, ",") {
+				if o = strings.TrimSpace(o); o != "" {
+					c.allowOrigins = append(c.allowOrigins, o)
+				}
+			}
+		}
 	}
 }
 
@@ -51,6 +71,16 @@ func WithAllowCredentials(allow bool) Option {
 	}
 }
 
+// WithAllowPrivateNetwork makes preflight requests that carry
+// Access-Control-Request-Private-Network, which Chrome sends before requests
+// from public pages to private network addresses, get
+// Access-Control-Allow-Private-Network: true.
+func WithAllowPrivateNetwork(allow bool) Option {
+	return func(c *Config) {
+		c.allowPrivateNet = allow
+	}
+}
+
 // WithMaxAge sets the Access-Control-Max-Age header.
 func WithMaxAge(seconds int) Option {
 	return func(c *Config) {
@@ -58,8 +88,36 @@ func WithMaxAge(seconds int) Option {
 	}
 }
 
+// ownedHeaders are the response headers set by New. They are cleared before
+// New sets its own, so that when several CORS middlewares run, e.g. a global
+// one and a group one, the innermost policy applies in full.
+var ownedHeaders = []string{
+	"Access-Control-Allow-Origin",
+	"Access-Control-Allow-Methods",
+	"Access-Control-Allow-Headers",
+	"Access-Control-Allow-Credentials",
+	"Access-Control-Allow-Private-Network",
+	"Access-Control-Max-Age",
+}
+
 // New returns a middleware that adds CORS headers with customizable options.
 // If no options are provided, sensible defaults are used.
+//
+// The middleware can be attached to a group to give it its own policy.
+// Preflight requests only reach group middleware through a matching OPTIONS
+// route, which the middleware answers itself:
+//
+//	partner := router.Group("/partner", cors.New(cors.WithAllowOrigin("https://partner.example.com")))
+//	partner.Options("/{path...}", func(http.ResponseWriter, *http.Request) error { return nil })
+//
+// Limitation: a global CORS middleware answers every preflight request
+// itself, before group middleware runs, so a group's policy never applies
+// to preflights while a global one is installed. For actual requests the
+// group's headers replace the global ones. Browsers check the preflight
+// against the global policy and the actual response against the group's,
+// and block the request when the two disagree, e.g. on the allowed origin.
+// Routes that need a preflight with their own policy must therefore not be
+// behind a global CORS middleware; use per-group policies throughout.
 func New(options ...Option) func(next http.Handler) http.Handler {
 	// Default configuration
 	cfg := &Config{
@@ -76,7 +134,18 @@ func New(options ...Option) func(next http.Handler) http.Handler {
 
 	return func(next http.Handler) http.Handler {
 		return httpx.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
-			w.Header().Set("Access-Control-Allow-Origin", cfg.allowOrigin)
+			for _, header := range ownedHeaders {
+				w.Header().Del(header)
+			}
+
+			if cfg.allowOrigins == nil {
+				w.Header().Set("Access-Control-Allow-Origin", cfg.allowOrigin)
+			} else {
+				middleware.AddVary(w.Header(), "Origin")
+				if origin := r.Header.Get("Origin"); slices.Contains(cfg.allowOrigins, origin) {
+					w.Header().Set("Access-Control-Allow-Origin", origin)
+				}
+			}
 			w.Header().Set("Access-Control-Allow-Methods", cfg.allowMethods)
 			w.Header().Set("Access-Control-Allow-Headers", cfg.allowHeaders)
 
@@ -89,6 +158,9 @@ func New(options ...Option) func(next http.Handler) http.Handler {
 			}
 
 			if r.Method == http.MethodOptions {
+				if cfg.allowPrivateNet && r.Header.Get("Access-Control-Request-Private-Network") == "true" {
+					w.Header().Set("Access-Control-Allow-Private-Network", "true")
+				}
 				w.WriteHeader(http.StatusOK)
 				return nil
 			}
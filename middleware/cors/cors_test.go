@@ -5,6 +5,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/vibe-go/vibe"
 	"github.com/vibe-go/vibe/httpx"
 	"github.com/vibe-go/vibe/middleware/cors"
 )
@@ -131,3 +132,167 @@ func TestCORSMiddleware(t *testing.T) {
 		}
 	})
 }
+
+func TestAllowOriginList(t *testing.T) {
+	handler := httpx.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) error {
+		w.WriteHeader(http.StatusOK)
+		return nil
+	})
+	wrapped := cors.New(cors.WithAllowOrigin("https://app.example.com, https://admin.example.com"))(handler)
+
+	tests := []struct {
+		origin   string
+		expected string
+	}{
+		{"https://admin.example.com", "https://admin.example.com"},
+		{"https://app.example.com", "https://app.example.com"},
+		{"https://evil.example.com", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.origin, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Origin", tt.origin)
+			w := httptest.NewRecorder()
+
+			wrapped.ServeHTTP(w, req)
+
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.expected {
+				t.Errorf("Expected Access-Control-Allow-Origin to be '%s', got '%s'", tt.expected, got)
+			}
+			if w.Header().Get("Vary") != "Origin" {
+				t.Errorf("Expected Vary to be 'Origin', got '%s'", w.Header().Get("Vary"))
+			}
+		})
+	}
+}
+
+func TestAllowPrivateNetwork(t *testing.T) {
+	handler := httpx.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) error {
+		w.WriteHeader(http.StatusOK)
+		return nil
+	})
+
+	preflight := func(wrapped http.Handler) string {
+		req := httptest.NewRequest(http.MethodOptions, "/", nil)
+		req.Header.Set("Origin", "https://dashboard.example.com")
+		req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+		req.Header.Set("Access-Control-Request-Private-Network", "true")
+		w := httptest.NewRecorder()
+
+		wrapped.ServeHTTP(w, req)
+		return w.Header().Get("Access-Control-Allow-Private-Network")
+	}
+
+	t.Run("Enabled", func(t *testing.T) {
+		if got := preflight(cors.New(cors.WithAllowPrivateNetwork(true))(handler)); got != "true" {
+			t.Errorf("Expected Access-Control-Allow-Private-Network to be 'true', got '%s'", got)
+		}
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		if got := preflight(cors.New()(handler)); got != "" {
+			t.Errorf("Expected no Access-Control-Allow-Private-Network header, got '%s'", got)
+		}
+	})
+}
+
+func TestGroupScopedCORS(t *testing.T) {
+	ok := func(http.ResponseWriter, *http.Request) error { return nil }
+
+	router := vibe.New()
+
+	public := router.Group("/public", cors.New())
+	public.Get("/items", ok)
+
+	partner := router.Group("/partner", cors.New(cors.WithAllowOrigin("https://partner.example.com")))
+	partner.Get("/orders", ok)
+	partner.Options("/{path...}", ok)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		origin string
+	}{
+		{"Public", http.MethodGet, "/public/items", "*"},
+		{"Partner", http.MethodGet, "/partner/orders", "https://partner.example.com"},
+		{"PartnerPreflight", http.MethodOptions, "/partner/orders", "https://partner.example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("Origin", "https://partner.example.com")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.origin {
+				t.Errorf("Expected Access-Control-Allow-Origin to be '%s', got '%s'", tt.origin, got)
+			}
+		})
+	}
+
+	// A global policy answers preflights before the group's policy runs,
+	// as documented on New, so the two responses carry different origins.
+	t.Run("GlobalAndGroupPreflight", func(t *testing.T) {
+		router := vibe.New()
+		router.Use(cors.New(cors.WithAllowOrigin("https://app.example.com")))
+		partner := router.Group("/partner", cors.New(cors.WithAllowOrigin("https://partner.example.com")))
+		partner.Get("/orders", ok)
+		partner.Options("/{path...}", ok)
+
+		tests := []struct {
+			name   string
+			method string
+			origin string
+		}{
+			{"Preflight", http.MethodOptions, "https://app.example.com"},
+			{"Actual", http.MethodGet, "https://partner.example.com"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				req := httptest.NewRequest(tt.method, "/partner/orders", nil)
+				req.Header.Set("Origin", "https://partner.example.com")
+				w := httptest.NewRecorder()
+
+				router.ServeHTTP(w, req)
+
+				if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.origin {
+					t.Errorf("Expected Access-Control-Allow-Origin to be '%s', got '%s'", tt.origin, got)
+				}
+			})
+		}
+	})
+
+	t.Run("NestedPolicies", func(t *testing.T) {
+		router := vibe.New()
+		router.Use(cors.New(
+			cors.WithAllowOrigin("https://app.example.com, https://www.example.com"),
+			cors.WithAllowCredentials(true),
+		))
+		router.Group("/public", cors.New(cors.WithAllowOrigin("https://a.example.com, https://b.example.com"))).
+			Get("/items", ok)
+
+		req := httptest.NewRequest(http.MethodGet, "/public/items", nil)
+		req.Header.Set("Origin", "https://app.example.com")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if got := w.Header().Values("Access-Control-Allow-Origin"); len(got) != 0 {
+			t.Errorf("Expected no Access-Control-Allow-Origin header, got %v", got)
+		}
+		if got := w.Header().Get("Access-Control-Allow-Credentials"); got != "" {
+			t.Errorf("Expected no Access-Control-Allow-Credentials header, got '%s'", got)
+		}
+		if got := w.Header().Values("Vary"); len(got) != 1 {
+			t.Errorf("Expected a single Vary header, got %v", got)
+		}
+	})
+}
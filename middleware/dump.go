@@ -0,0 +1,243 @@
+package middleware
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// redactedHeaders are replaced with "[redacted]" in dumps, so that
+// credentials do not end up in logs.
+var redactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// redactedParams are the query parameters whose values are replaced with
+// "[redacted]" in dumps.
+var redactedParams = []string{"api_key"}
+
+// DumpOption configures the DumpRequest and DumpResponse middleware.
+type DumpOption func(*dumpConfig)
+
+// dumpConfig holds the configuration for the DumpRequest and DumpResponse
+// middleware.
+type dumpConfig struct {
+	disabled bool
+	maxBody  int64
+	redacted []string
+}
+
+// WithDumpEnabled turns the dump on or off, e.g. from the environment. A
+// disabled dump adds no overhead to requests.
+func WithDumpEnabled(enabled bool) DumpOption {
+	return func(c *dumpConfig) {
+		c.disabled = !enabled
+	}
+}
+
+// WithDumpBody includes the body, up to maxBytes, in the dump. For
+// DumpRequest the body is buffered with BufferBody, so handlers can still
+// read it, and larger bodies are rejected with 413 Request Entity Too Large.
+// For DumpResponse the body is sent as usual and only its first maxBytes are
+// logged.
+func WithDumpBody(maxBytes int64) DumpOption {
+	return func(c *dumpConfig) {
+		c.maxBody = maxBytes
+	}
+}
+
+// WithRedactedHeaders redacts the named headers in dumps, in addition to
+// Authorization, Proxy-Authorization, Cookie and Set-Cookie, e.g. for API
+// keys sent in a custom header.
+//
+// Example:
+//
+//	router.Use(middleware.DumpRequest(debugLogger, middleware.WithRedactedHeaders("X-Api-Key")))
+func WithRedactedHeaders(names ...string) DumpOption {
+	return func(c *dumpConfig) {
+		c.redacted = append(c.redacted, names...)
+	}
+}
+
+// newDumpConfig returns the dump configuration with options applied.
+func newDumpConfig(options []DumpOption) *dumpConfig {
+	cfg := &dumpConfig{redacted: slices.Clone(redactedHeaders)}
+	for _, option := range options {
+		option(cfg)
+	}
+	return cfg
+}
+
+// DumpRequest returns a middleware that logs each request in full, i.e. the
+// request line and headers and, with WithDumpBody, the body, for debugging
+// client issues. Credentials in the Authorization, Proxy-Authorization and
+// Cookie headers, in headers added with WithRedactedHeaders and in the
+// api_key query parameter are redacted.
+//
+// Example:
+//
+//	router.Use(middleware.DumpRequest(debugLogger,
+//		middleware.WithDumpEnabled(os.Getenv("DEBUG") != ""),
+//		middleware.WithDumpBody(64<<10),
+//	))
+func DumpRequest(logger *log.Logger, options ...DumpOption) func(next http.Handler) http.Handler {
+	if logger == nil {
+		logger = log.New(log.Writer(), "[debug] ", log.LstdFlags)
+	}
+
+	cfg := newDumpConfig(options)
+
+	return func(next http.Handler) http.Handler {
+		if cfg.disabled {
+			return next
+		}
+
+		dump := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			logger.Printf("request dump:\n%s", dumpRequest(r, cfg.redacted, cfg.maxBody > 0))
+			next.ServeHTTP(w, r)
+		})
+		if cfg.maxBody > 0 {
+			return BufferBody(cfg.maxBody)(dump)
+		}
+		return dump
+	}
+}
+
+// dumpRequest formats r with the redacted headers and query parameters
+// replaced. The body, if included, is the one buffered by BufferBody.
+func dumpRequest(r *http.Request, redacted []string, withBody bool) []byte {
+	clone := *r
+	clone.Header = redactHeaders(r.Header, redacted)
+	clone.URL = redactURL(r.URL)
+	clone.RequestURI = ""
+
+	out, err := httputil.DumpRequest(&clone, false)
+	if err != nil {
+		return []byte(err.Error())
+	}
+	if withBody {
+		body, _ := RawBody(r)
+		out = append(out, body...)
+	}
+	return out
+}
+
+// redactHeaders returns a copy of h with the values of the named headers
+// replaced with "[redacted]".
+func redactHeaders(h http.Header, names []string) http.Header {
+	h = h.Clone()
+	for _, name := range names {
+		if h.Get(name) != "" {
+			h.Set(name, "[redacted]")
+		}
+	}
+	return h
+}
+
+// redactURL returns a copy of u with the values of the redacted query
+// parameters replaced with "[redacted]". The order of the other parameters
+// and their encoding are kept as sent.
+func redactURL(u *url.URL) *url.URL {
+	clone := *u
+	pairs := strings.Split(u.RawQuery, "&")
+	for i, pair := range pairs {
+		key, _, _ := strings.Cut(pair, "=")
+		if name, err := url.QueryUnescape(key); err == nil && slices.Contains(redactedParams, name) {
+			pairs[i] = key + "=[redacted]"
+		}
+	}
+	clone.RawQuery = strings.Join(pairs, "&")
+	return &clone
+}
+
+// DumpResponse returns a middleware that logs each response in full, i.e.
+// the status and headers and, with WithDumpBody, the start of the body, for
+// debugging client-side parse failures. The response is passed on to the
+// client unchanged as it is written; only the dump redacts headers, as
+// DumpRequest does.
+//
+// Example:
+//
+//	router.Use(middleware.DumpResponse(debugLogger, middleware.WithDumpBody(4096)))
+func DumpResponse(logger *log.Logger, options ...DumpOption) func(next http.Handler) http.Handler {
+	if logger == nil {
+		logger = log.New(log.Writer(), "[debug] ", log.LstdFlags)
+	}
+
+	cfg := newDumpConfig(options)
+
+	return func(next http.Handler) http.Handler {
+		if cfg.disabled {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			dw := &dumpWriter{ResponseWriter: w, maxBody: cfg.maxBody}
+			next.ServeHTTP(dw, r)
+			logger.Printf("response dump for %s %s:\n%s", r.Method, redactURL(r.URL).RequestURI(), dw.dump(cfg.redacted))
+		})
+	}
+}
+
+// dumpWriter records the status and the start of the body of a response.
+type dumpWriter struct {
+	http.ResponseWriter
+	status  int
+	size    int
+	body    bytes.Buffer
+	maxBody int64
+}
+
+// WriteHeader records and writes the status code.
+func (dw *dumpWriter) WriteHeader(statusCode int) {
+	if dw.status == 0 {
+		dw.status = statusCode
+	}
+	dw.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write records up to maxBody bytes of the body and writes b.
+func (dw *dumpWriter) Write(b []byte) (int, error) {
+	if dw.status == 0 {
+		dw.status = http.StatusOK
+	}
+	if room := dw.maxBody - int64(dw.body.Len()); room > 0 {
+		dw.body.Write(b[:min(int64(len(b)), room)])
+	}
+	n, err := dw.ResponseWriter.Write(b)
+	dw.size += n
+	return n, err
+}
+
+// Flush implements http.Flusher if the underlying writer supports it.
+func (dw *dumpWriter) Flush() {
+	if f, ok := dw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying http.ResponseWriter.
+func (dw *dumpWriter) Unwrap() http.ResponseWriter {
+	return dw.ResponseWriter
+}
+
+// dump formats the recorded response with the named headers redacted.
+func (dw *dumpWriter) dump(redacted []string) []byte {
+	status := dw.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "%d %s\r\n", status, http.StatusText(status))
+	redactHeaders(dw.Header(), redacted).Write(&out)
+	out.WriteString("\r\n")
+	out.Write(dw.body.Bytes())
+	if dw.maxBody > 0 && dw.size > dw.body.Len() {
+		fmt.Fprintf(&out, "... (%d more bytes)", dw.size-dw.body.Len())
+	}
+	return out.Bytes()
+}
@@ -0,0 +1,137 @@
+package middleware_test
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/vibe-go/vibe/middleware"
+)
+
+func TestDumpRequest(t *testing.T) {
+	const payload = `{"name":"test"}`
+
+	var received string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	newRequest := func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/orders?dry_run=1", strings.NewReader(payload))
+		req.Header.Set("X-Client-Version", "3.2.1")
+		req.Header.Set("Authorization", "Bearer secret-token")
+		return req
+	}
+
+	// Test case: the dump holds the request and the handler still reads the body
+	t.Run("WithBody", func(t *testing.T) {
+		var buf bytes.Buffer
+		wrapped := middleware.DumpRequest(log.New(&buf, "", 0), middleware.WithDumpBody(1024))(handler)
+
+		wrapped.ServeHTTP(httptest.NewRecorder(), newRequest())
+
+		dump := buf.String()
+		for _, expected := range []string{"POST /orders?dry_run=1", "X-Client-Version: 3.2.1", payload} {
+			if !strings.Contains(dump, expected) {
+				t.Errorf("Expected dump to contain %q, got:\n%s", expected, dump)
+			}
+		}
+		if strings.Contains(dump, "secret-token") {
+			t.Errorf("Expected Authorization to be redacted, got:\n%s", dump)
+		}
+		if received != payload {
+			t.Errorf("Expected handler to read body %s, got %s", payload, received)
+		}
+	})
+
+	// Test case: extra headers and the api_key parameter are redacted
+	t.Run("Redacted", func(t *testing.T) {
+		var buf bytes.Buffer
+		wrapped := middleware.DumpRequest(log.New(&buf, "", 0), middleware.WithRedactedHeaders("X-Api-Key"))(handler)
+
+		req := httptest.NewRequest(http.MethodGet, "/orders?dry_run=1&api_key=key-123", nil)
+		req.Header.Set("X-Api-Key", "header-key")
+		wrapped.ServeHTTP(httptest.NewRecorder(), req)
+
+		dump := buf.String()
+		for _, secret := range []string{"key-123", "header-key"} {
+			if strings.Contains(dump, secret) {
+				t.Errorf("Expected %q to be redacted, got:\n%s", secret, dump)
+			}
+		}
+		expected := "GET /orders?dry_run=1&api_key=[redacted]"
+		if !strings.Contains(dump, expected) {
+			t.Errorf("Expected dump to contain %q, got:\n%s", expected, dump)
+		}
+		if req.URL.Query().Get("api_key") != "key-123" {
+			t.Errorf("Expected request URL to be left unchanged, got %s", req.URL)
+		}
+	})
+
+	// Test case: a disabled dump logs nothing
+	t.Run("Disabled", func(t *testing.T) {
+		var buf bytes.Buffer
+		wrapped := middleware.DumpRequest(log.New(&buf, "", 0), middleware.WithDumpEnabled(false))(handler)
+
+		wrapped.ServeHTTP(httptest.NewRecorder(), newRequest())
+
+		if buf.Len() != 0 {
+			t.Errorf("Expected no dump, got:\n%s", buf.String())
+		}
+		if received != payload {
+			t.Errorf("Expected handler to read body %s, got %s", payload, received)
+		}
+	})
+}
+
+func TestDumpResponse(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":"42","name":"` + strings.Repeat("x", 100) + `"}`))
+	})
+
+	var buf bytes.Buffer
+	wrapped := middleware.DumpResponse(log.New(&buf, "", 0), middleware.WithDumpBody(16))(handler)
+
+	w := httptest.NewRecorder()
+	wrapped.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/items", nil))
+
+	dump := buf.String()
+	for _, expected := range []string{"201 Created", "Content-Type: application/json", `{"id":"42","name`, "more bytes"} {
+		if !strings.Contains(dump, expected) {
+			t.Errorf("Expected dump to contain %q, got:\n%s", expected, dump)
+		}
+	}
+	if w.Code != http.StatusCreated || !strings.HasSuffix(w.Body.String(), `"}`) {
+		t.Errorf("Expected the full response to reach the client, got %d %s", w.Code, w.Body.String())
+	}
+
+	// Test case: response headers and the request's api_key are redacted
+	t.Run("Redacted", func(t *testing.T) {
+		var buf bytes.Buffer
+		wrapped := middleware.DumpResponse(log.New(&buf, "", 0))(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "cookie-secret"})
+			w.WriteHeader(http.StatusOK)
+		}))
+
+		w := httptest.NewRecorder()
+		wrapped.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items?api_key=key-123", nil))
+
+		dump := buf.String()
+		for _, secret := range []string{"key-123", "cookie-secret"} {
+			if strings.Contains(dump, secret) {
+				t.Errorf("Expected %q to be redacted, got:\n%s", secret, dump)
+			}
+		}
+		if !strings.Contains(w.Header().Get("Set-Cookie"), "cookie-secret") {
+			t.Errorf("Expected the cookie to reach the client, got %q", w.Header().Get("Set-Cookie"))
+		}
+	})
+}
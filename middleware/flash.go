@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/vibe-go/vibe/cookie"
+	"github.com/vibe-go/vibe/middleware/flash"
+)
+
+// Flash returns a middleware that carries one-time flash messages from one
+// request to the next in a cookie signed by signer, e.g. for
+// redirect-after-POST flows. It is flash.New; handlers add and read the
+// messages with flash.From, since a request accessor cannot share the name
+// Flash in this package.
+//
+// Example:
+//
+//	router.Use(middleware.Flash(cookie.Signed(secret)))
+//	...
+//	flash.From(r).Add("info", "Saved!")
+//	http.Redirect(w, r, "/items", http.StatusSeeOther)
+func Flash(signer *cookie.Signer, options ...flash.Option) func(next http.Handler) http.Handler {
+	return flash.New(signer, options...)
+}
@@ -0,0 +1,168 @@
+// Package flash provides one-time flash message middleware for the Vibe framework.
+//
+// Flash messages are added during one request, stored in a signed cookie and
+// made available to the next request only, which makes them well suited to
+// redirect-after-POST flows.
+package flash
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/vibe-go/vibe/cookie"
+	"github.com/vibe-go/vibe/ctxval"
+	"github.com/vibe-go/vibe/httpx"
+)
+
+// DefaultCookieName is the default name of the cookie holding flash messages.
+const DefaultCookieName = "_flash"
+
+// Config holds the configuration for flash middleware.
+type Config struct {
+	cookieName string
+	options    []cookie.Option
+}
+
+// Option defines a function that configures flash options.
+type Option func(*Config)
+
+// WithCookieName sets the name of the cookie holding flash messages.
+func WithCookieName(name string) Option {
+	return func(c *Config) {
+		c.cookieName = name
+	}
+}
+
+// WithCookieOptions sets the attributes of the flash cookie.
+func WithCookieOptions(options ...cookie.Option) Option {
+	return func(c *Config) {
+		c.options = options
+	}
+}
+
+// Messages holds the flash messages of the current request.
+type Messages struct {
+	mu       sync.Mutex
+	incoming map[string][]string
+	outgoing map[string][]string
+}
+
+// Add queues a message of the given kind (e.g. "info", "error") for the next request.
+func (m *Messages) Add(kind, message string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.outgoing == nil {
+		m.outgoing = make(map[string][]string)
+	}
+	m.outgoing[kind] = append(m.outgoing[kind], message)
+}
+
+// Get returns the messages of the given kind set by the previous request.
+func (m *Messages) Get(kind string) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.incoming[kind]
+}
+
+// All returns all messages set by the previous request, keyed by kind.
+func (m *Messages) All() map[string][]string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.incoming
+}
+
+// From returns the flash messages for the request.
+// If the flash middleware is not installed, an empty set is returned and
+// messages added to it are discarded.
+//
+// Example:
+//
+//	flash.From(r).Add("info", "Saved!")
+//	http.Redirect(w, r, "/items", http.StatusSeeOther)
+func From(r *http.Request) *Messages {
+	if m, ok := ctxval.Get[*Messages](r); ok {
+		return m
+	}
+	return &Messages{}
+}
+
+// New returns a middleware that loads flash messages from a cookie signed by
+// signer and persists newly added messages for the next request. Messages
+// loaded from the cookie are cleared in the same response, so each message
+// is delivered exactly once.
+func New(signer *cookie.Signer, options ...Option) func(next http.Handler) http.Handler {
+	cfg := &Config{
+		cookieName: DefaultCookieName,
+	}
+
+	for _, option := range options {
+		option(cfg)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return httpx.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			m := &Messages{}
+			loaded := false
+			if raw, err := signer.Get(r, cfg.cookieName); err == nil {
+				loaded = json.Unmarshal([]byte(raw), &m.incoming) == nil
+			}
+
+			fw := &flashWriter{ResponseWriter: w}
+			fw.commit = func() {
+				m.mu.Lock()
+				outgoing := m.outgoing
+				m.mu.Unlock()
+
+				if len(outgoing) > 0 {
+					data, err := json.Marshal(outgoing)
+					if err == nil {
+						signer.Set(w, cfg.cookieName, string(data), cfg.options...)
+						return
+					}
+				}
+				if loaded {
+					signer.Delete(w, cfg.cookieName, cfg.options...)
+				}
+			}
+
+			next.ServeHTTP(fw, ctxval.Set(r, m))
+			fw.commitOnce()
+			return nil
+		})
+	}
+}
+
+// flashWriter writes the flash cookie just before the response headers are sent.
+type flashWriter struct {
+	http.ResponseWriter
+	commit    func()
+	committed bool
+}
+
+func (w *flashWriter) commitOnce() {
+	if !w.committed {
+		w.committed = true
+		w.commit()
+	}
+}
+
+// WriteHeader commits the flash cookie and writes the status code.
+func (w *flashWriter) WriteHeader(statusCode int) {
+	w.commitOnce()
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write commits the flash cookie and writes the body.
+func (w *flashWriter) Write(b []byte) (int, error) {
+	w.commitOnce()
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the underlying http.ResponseWriter.
+func (w *flashWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
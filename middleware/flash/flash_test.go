@@ -0,0 +1,80 @@
+package flash_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/vibe-go/vibe/cookie"
+	"github.com/vibe-go/vibe/httpx"
+	"github.com/vibe-go/vibe/middleware/flash"
+)
+
+func TestFlash(t *testing.T) {
+	signer := cookie.Signed([]byte("secret"))
+
+	var got []string
+	handler := httpx.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		if r.Method == http.MethodPost {
+			flash.From(r).Add("info", "Saved!")
+			http.Redirect(w, r, "/", http.StatusSeeOther)
+			return nil
+		}
+		got = flash.From(r).Get("info")
+		w.WriteHeader(http.StatusOK)
+		return nil
+	})
+
+	wrapped := flash.New(signer)(handler)
+
+	// First request sets the flash message
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	w := httptest.NewRecorder()
+	wrapped.ServeHTTP(w, req)
+
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("Expected 1 cookie, got %d", len(cookies))
+	}
+
+	// Second request reads it and clears the cookie
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(cookies[0])
+	w = httptest.NewRecorder()
+	wrapped.ServeHTTP(w, req)
+
+	if len(got) != 1 || got[0] != "Saved!" {
+		t.Errorf("Expected flash message 'Saved!', got %v", got)
+	}
+
+	cookies = w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].MaxAge >= 0 {
+		t.Fatalf("Expected flash cookie to be expired, got %+v", cookies)
+	}
+
+	// Third request without the expired cookie sees nothing
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	w = httptest.NewRecorder()
+	wrapped.ServeHTTP(w, req)
+
+	if len(got) != 0 {
+		t.Errorf("Expected no flash messages, got %v", got)
+	}
+}
+
+func TestFlashTamperedCookie(t *testing.T) {
+	handler := httpx.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		if len(flash.From(r).All()) != 0 {
+			t.Errorf("Expected tampered flash cookie to be ignored")
+		}
+		w.WriteHeader(http.StatusOK)
+		return nil
+	})
+
+	wrapped := flash.New(cookie.Signed([]byte("secret")))(handler)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: flash.DefaultCookieName, Value: "eyJpbmZvIjpbIngiXX0.bad"})
+	w := httptest.NewRecorder()
+	wrapped.ServeHTTP(w, req)
+}
@@ -0,0 +1,52 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/vibe-go/vibe/cookie"
+	"github.com/vibe-go/vibe/httpx"
+	"github.com/vibe-go/vibe/middleware"
+	"github.com/vibe-go/vibe/middleware/flash"
+)
+
+func TestFlash(t *testing.T) {
+	var got []string
+	handler := httpx.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		if r.Method == http.MethodPost {
+			flash.From(r).Add("info", "Saved!")
+			http.Redirect(w, r, "/", http.StatusSeeOther)
+			return nil
+		}
+		got = flash.From(r).Get("info")
+		w.WriteHeader(http.StatusOK)
+		return nil
+	})
+
+	wrapped := middleware.Flash(cookie.Signed([]byte("secret")))(handler)
+
+	w := httptest.NewRecorder()
+	wrapped.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", nil))
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("Expected 1 cookie, got %d", len(cookies))
+	}
+
+	// Test case: the message is read exactly once on the next request
+	for i, expected := range []int{1, 0} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		for _, c := range cookies {
+			if c.MaxAge >= 0 {
+				req.AddCookie(c)
+			}
+		}
+		w = httptest.NewRecorder()
+		wrapped.ServeHTTP(w, req)
+		cookies = w.Result().Cookies()
+
+		if len(got) != expected {
+			t.Errorf("Expected %d flash messages on read %d, got %v", expected, i+1, got)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/vibe-go/vibe/httpx"
+)
+
+// RequireHeaders returns a middleware that rejects requests missing any of
+// the named headers with 400 Bad Request. The error message names every
+// missing header. A header that is present but empty counts as missing.
+//
+// Example:
+//
+//	api := router.Group("/api", middleware.RequireHeaders("X-API-Version"))
+func RequireHeaders(names ...string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return httpx.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			var missing []string
+			for _, name := range names {
+				if r.Header.Get(name) == "" {
+					missing = append(missing, name)
+				}
+			}
+
+			switch len(missing) {
+			case 0:
+				next.ServeHTTP(w, r)
+				return nil
+			case 1:
+				return httpx.BadRequest(w, fmt.Errorf("missing required header %s", missing[0]))
+			default:
+				return httpx.BadRequest(w, fmt.Errorf("missing required headers %s", strings.Join(missing, ", ")))
+			}
+		})
+	}
+}
+
+// AddVary adds value to the response's Vary header unless it is already
+// listed, so that middleware and handlers can each declare what they vary
+// on without repeating one another.
+func AddVary(h http.Header, value string) {
+	for _, v := range h.Values("Vary") {
+		for _, field := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(field), value) {
+				return
+			}
+		}
+	}
+	h.Add("Vary", value)
+}
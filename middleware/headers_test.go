@@ -0,0 +1,60 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/vibe-go/vibe/middleware"
+)
+
+func TestRequireHeaders(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	wrapped := middleware.RequireHeaders("X-API-Version", "x-tenant-id")(handler)
+
+	// Test case: all required headers present
+	t.Run("Present", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-API-Version", "2")
+		req.Header.Set("X-Tenant-ID", "acme")
+		w := httptest.NewRecorder()
+
+		wrapped.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+		}
+	})
+
+	// Test case: a missing header is named in the error
+	t.Run("Missing", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-Tenant-ID", "acme")
+		w := httptest.NewRecorder()
+
+		wrapped.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status code %d, got %d", http.StatusBadRequest, w.Code)
+		}
+		if !strings.Contains(w.Body.String(), "missing required header X-API-Version") {
+			t.Errorf("Expected error naming X-API-Version, got '%s'", w.Body.String())
+		}
+	})
+
+	// Test case: all missing headers are listed
+	t.Run("SeveralMissing", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		w := httptest.NewRecorder()
+
+		wrapped.ServeHTTP(w, req)
+
+		if !strings.Contains(w.Body.String(), "missing required headers X-API-Version, x-tenant-id") {
+			t.Errorf("Expected error naming both headers, got '%s'", w.Body.String())
+		}
+	})
+}
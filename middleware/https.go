@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HTTPSOption configures the RequireHTTPS middleware.
+type HTTPSOption func(*httpsConfig)
+
+// httpsConfig holds the configuration for the RequireHTTPS middleware.
+type httpsConfig struct {
+	hstsMaxAge        time.Duration
+	includeSubdomains bool
+	trust             *ProxyTrust
+}
+
+// WithHSTS makes RequireHTTPS send a Strict-Transport-Security header with
+// the given max-age on HTTPS responses, telling browsers to use HTTPS for
+// the host from then on. If includeSubdomains is set, the policy also
+// covers all subdomains.
+func WithHSTS(maxAge time.Duration, includeSubdomains bool) HTTPSOption {
+	return func(c *httpsConfig) {
+		c.hstsMaxAge = maxAge
+		c.includeSubdomains = includeSubdomains
+	}
+}
+
+// WithTrustedProxies makes RequireHTTPS honour X-Forwarded-Proto on
+// requests from the given proxies. Without it the header is ignored, as it
+// is from every other peer, since clients could otherwise set it to skip the
+// redirect.
+func WithTrustedProxies(trust *ProxyTrust) HTTPSOption {
+	return func(c *httpsConfig) {
+		c.trust = trust
+	}
+}
+
+// RequireHTTPS returns a middleware that redirects plain HTTP requests to
+// the same URL over HTTPS with 308 Permanent Redirect, which preserves the
+// method and body. A request counts as HTTPS if it arrived over TLS or if a
+// TLS-terminating proxy trusted with WithTrustedProxies marked it with
+// "X-Forwarded-Proto: https", so redirected requests coming back through the
+// proxy are not redirected again. The proxy is recognised by the peer
+// address, even after RealIP has replaced r.RemoteAddr with the client's.
+//
+// Example:
+//
+//	proxies := middleware.TrustedProxies("10.0.0.0/8")
+//	router.Use(middleware.RealIP(proxies))
+//	router.Use(middleware.RequireHTTPS(
+//		middleware.WithTrustedProxies(proxies),
+//		middleware.WithHSTS(365*24*time.Hour, true),
+//	))
+func RequireHTTPS(options ...HTTPSOption) func(next http.Handler) http.Handler {
+	cfg := &httpsConfig{}
+	for _, option := range options {
+		option(cfg)
+	}
+
+	var hsts string
+	if cfg.hstsMaxAge > 0 {
+		hsts = "max-age=" + strconv.FormatInt(int64(cfg.hstsMaxAge/time.Second), 10)
+		if cfg.includeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !isHTTPS(r, cfg.trust) {
+				http.Redirect(w, r, "https://"+r.Host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+				return
+			}
+
+			if hsts != "" {
+				w.Header().Set("Strict-Transport-Security", hsts)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// isHTTPS reports whether the request reached the client-facing server over
+// HTTPS, either directly or through a proxy setting X-Forwarded-Proto. The
+// header is only believed from a proxy in trust.
+func isHTTPS(r *http.Request, trust *ProxyTrust) bool {
+	if r.TLS != nil {
+		return true
+	}
+	if !trust.Trusts(PeerAddr(r)) {
+		return false
+	}
+	// Only the first proxy's view of the scheme is relevant.
+	proto, _, _ := strings.Cut(r.Header.Get("X-Forwarded-Proto"), ",")
+	return strings.EqualFold(strings.TrimSpace(proto), "https")
+}
@@ -0,0 +1,72 @@
+package middleware_test
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/vibe-go/vibe/middleware"
+)
+
+func TestRequireHTTPS(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	// httptest requests come from 192.0.2.1, which stands in for the proxy.
+	wrapped := middleware.RequireHTTPS(
+		middleware.WithHSTS(24*time.Hour, true),
+		middleware.WithTrustedProxies(middleware.TrustedProxies("192.0.2.1")),
+	)(handler)
+
+	// Test case: plain HTTP behind a proxy is redirected
+	t.Run("ForwardedHTTP", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "http://example.com/orders?id=1", nil)
+		req.Header.Set("X-Forwarded-Proto", "http")
+		w := httptest.NewRecorder()
+
+		wrapped.ServeHTTP(w, req)
+
+		if w.Code != http.StatusPermanentRedirect {
+			t.Errorf("Expected status code %d, got %d", http.StatusPermanentRedirect, w.Code)
+		}
+		if w.Header().Get("Location") != "https://example.com/orders?id=1" {
+			t.Errorf("Expected Location 'https://example.com/orders?id=1', got '%s'", w.Header().Get("Location"))
+		}
+		if w.Header().Get("Strict-Transport-Security") != "" {
+			t.Errorf("Expected no HSTS header on plain HTTP response")
+		}
+	})
+
+	// Test case: HTTPS reported by the proxy passes through
+	t.Run("ForwardedHTTPS", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+		req.Header.Set("X-Forwarded-Proto", "https")
+		w := httptest.NewRecorder()
+
+		wrapped.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+		}
+		expected := "max-age=86400; includeSubDomains"
+		if w.Header().Get("Strict-Transport-Security") != expected {
+			t.Errorf("Expected HSTS '%s', got '%s'", expected, w.Header().Get("Strict-Transport-Security"))
+		}
+	})
+
+	// Test case: direct TLS connections pass through
+	t.Run("TLS", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "https://example.com/", nil)
+		req.TLS = &tls.ConnectionState{}
+		w := httptest.NewRecorder()
+
+		wrapped.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+		}
+	})
+}
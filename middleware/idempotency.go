@@ -0,0 +1,256 @@
+package middleware
+
+import (
+	"bytes"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/vibe-go/vibe/httpx"
+)
+
+// IdempotencyKeyHeader is the header carrying the client's idempotency key.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// StoredResponse is a response recorded by the Idempotency middleware.
+// Header holds only the headers set by the wrapped handler, never
+// Set-Cookie.
+type StoredResponse struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// IdempotencyStore stores responses by idempotency key.
+// Implementations must be safe for concurrent use.
+type IdempotencyStore interface {
+	// Get returns the response stored under key, if any.
+	Get(key string) (*StoredResponse, bool)
+	// Set stores resp under key.
+	Set(key string, resp *StoredResponse)
+}
+
+// MemoryIdempotencyStore is an IdempotencyStore that keeps responses in
+// process memory for a fixed TTL.
+type MemoryIdempotencyStore struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]memoryEntry
+}
+
+type memoryEntry struct {
+	resp    *StoredResponse
+	expires time.Time
+}
+
+// NewMemoryIdempotencyStore creates an in-memory store whose entries expire after ttl.
+func NewMemoryIdempotencyStore(ttl time.Duration) *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{
+		ttl:     ttl,
+		entries: make(map[string]memoryEntry),
+	}
+}
+
+// Get implements IdempotencyStore.
+func (s *MemoryIdempotencyStore) Get(key string) (*StoredResponse, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expires) {
+		delete(s.entries, key)
+		return nil, false
+	}
+	return entry.resp, true
+}
+
+// Set implements IdempotencyStore.
+func (s *MemoryIdempotencyStore) Set(key string, resp *StoredResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for k, entry := range s.entries {
+		if now.After(entry.expires) {
+			delete(s.entries, k)
+		}
+	}
+	s.entries[key] = memoryEntry{resp: resp, expires: now.Add(s.ttl)}
+}
+
+// IdempotencyOption configures the Idempotency middleware.
+type IdempotencyOption func(*idempotencyConfig)
+
+// idempotencyConfig holds the configuration for the Idempotency middleware.
+type idempotencyConfig struct {
+	keyFunc func(r *http.Request, key string) string
+}
+
+// WithIdempotencyKeyFunc sets the function deriving the store key from the
+// request and its Idempotency-Key, e.g. to scope keys to the authenticated
+// caller so that one client cannot replay another's response by reusing
+// its key. The default combines the method, the path and the key.
+//
+// Example:
+//
+//	middleware.Idempotency(store, middleware.WithIdempotencyKeyFunc(
+//		func(r *http.Request, key string) string {
+//			return userID(r) + " " + r.Method + " " + r.URL.Path + " " + key
+//		}))
+func WithIdempotencyKeyFunc(keyFunc func(r *http.Request, key string) string) IdempotencyOption {
+	return func(c *idempotencyConfig) {
+		c.keyFunc = keyFunc
+	}
+}
+
+// defaultIdempotencyKey scopes key to the request's method and path.
+func defaultIdempotencyKey(r *http.Request, key string) string {
+	return r.Method + " " + r.URL.Path + " " + key
+}
+
+// keyedMutex serializes work per key.
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*keyedLock
+}
+
+type keyedLock struct {
+	sync.Mutex
+	refs int
+}
+
+func (k *keyedMutex) lock(key string) func() {
+	k.mu.Lock()
+	l, ok := k.locks[key]
+	if !ok {
+		l = &keyedLock{}
+		k.locks[key] = l
+	}
+	l.refs++
+	k.mu.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		k.mu.Lock()
+		l.refs--
+		if l.refs == 0 {
+			delete(k.locks, key)
+		}
+		k.mu.Unlock()
+	}
+}
+
+// Idempotency returns a middleware that makes requests carrying an
+// Idempotency-Key header safe to retry. The first response for a key is
+// recorded in store and replayed for later requests with the same key,
+// method and path, without running the handler again. Concurrent requests
+// with the same key are serialized. Server errors (5xx) are not recorded,
+// so such requests can be retried.
+//
+// Only the headers set by the handler are recorded; headers set by
+// middleware outside Idempotency, such as X-Request-ID or CORS headers, are
+// set afresh on replays, and Set-Cookie is never recorded. Keys are shared
+// by all clients unless scoped with WithIdempotencyKeyFunc.
+//
+// Example:
+//
+//	store := middleware.NewMemoryIdempotencyStore(24 * time.Hour)
+//	router.Post("/payments", createPayment, middleware.Idempotency(store))
+func Idempotency(store IdempotencyStore, options ...IdempotencyOption) func(next http.Handler) http.Handler {
+	cfg := &idempotencyConfig{keyFunc: defaultIdempotencyKey}
+	for _, option := range options {
+		option(cfg)
+	}
+	locks := &keyedMutex{locks: make(map[string]*keyedLock)}
+
+	return func(next http.Handler) http.Handler {
+		return httpx.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return nil
+			}
+			key = cfg.keyFunc(r, key)
+
+			unlock := locks.lock(key)
+			defer unlock()
+
+			if stored, ok := store.Get(key); ok {
+				for name, values := range stored.Header {
+					w.Header()[name] = values
+				}
+				w.WriteHeader(stored.Status)
+				_, err := w.Write(stored.Body)
+				return err
+			}
+
+			rec := &recordingWriter{ResponseWriter: w, before: w.Header().Clone()}
+			next.ServeHTTP(rec, r)
+
+			status := rec.status
+			if status == 0 {
+				status = http.StatusOK
+				rec.header = handlerHeaders(rec.before, w.Header())
+			}
+			if status < http.StatusInternalServerError {
+				store.Set(key, &StoredResponse{
+					Status: status,
+					Header: rec.header,
+					Body:   rec.body.Bytes(),
+				})
+			}
+			return nil
+		})
+	}
+}
+
+// handlerHeaders returns the headers in after that were added or changed
+// since before, except Set-Cookie.
+func handlerHeaders(before, after http.Header) http.Header {
+	header := make(http.Header)
+	for name, values := range after {
+		if name != "Set-Cookie" && !slices.Equal(before[name], values) {
+			header[name] = slices.Clone(values)
+		}
+	}
+	return header
+}
+
+// recordingWriter writes through to the client while keeping a copy of the
+// status, body and the headers set by the handler.
+type recordingWriter struct {
+	http.ResponseWriter
+	before http.Header
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+// WriteHeader records and writes the status code. Informational (1xx)
+// statuses are passed through without being recorded.
+func (w *recordingWriter) WriteHeader(statusCode int) {
+	if w.status == 0 && statusCode >= http.StatusOK {
+		w.status = statusCode
+		w.header = handlerHeaders(w.before, w.Header())
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write records and writes the body.
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the underlying http.ResponseWriter.
+func (w *recordingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
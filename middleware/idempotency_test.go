@@ -0,0 +1,132 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/vibe-go/vibe/httpx"
+	"github.com/vibe-go/vibe/middleware"
+)
+
+func TestIdempotency(t *testing.T) {
+	var calls atomic.Int32
+	handler := httpx.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) error {
+		n := calls.Add(1)
+		time.Sleep(10 * time.Millisecond)
+		w.Header().Set("X-Call", strconv.Itoa(int(n)))
+		return httpx.JSON(w, map[string]int{"call": int(n)}, http.StatusCreated)
+	})
+
+	// Test case: duplicate requests replay the first response
+	t.Run("Replay", func(t *testing.T) {
+		calls.Store(0)
+		wrapped := middleware.Idempotency(middleware.NewMemoryIdempotencyStore(time.Minute))(handler)
+
+		var wg sync.WaitGroup
+		recorders := make([]*httptest.ResponseRecorder, 2)
+		for i := range recorders {
+			recorders[i] = httptest.NewRecorder()
+			wg.Add(1)
+			go func(w *httptest.ResponseRecorder) {
+				defer wg.Done()
+				req := httptest.NewRequest(http.MethodPost, "/payments", nil)
+				req.Header.Set(middleware.IdempotencyKeyHeader, "key-1")
+				wrapped.ServeHTTP(w, req)
+			}(recorders[i])
+		}
+		wg.Wait()
+
+		if calls.Load() != 1 {
+			t.Errorf("Expected handler to run once, ran %d times", calls.Load())
+		}
+
+		first, second := recorders[0], recorders[1]
+		if first.Code != http.StatusCreated || second.Code != http.StatusCreated {
+			t.Errorf("Expected both status codes %d, got %d and %d", http.StatusCreated, first.Code, second.Code)
+		}
+		if first.Body.String() != second.Body.String() {
+			t.Errorf("Expected identical bodies, got %s and %s", first.Body.String(), second.Body.String())
+		}
+		if first.Header().Get("X-Call") != second.Header().Get("X-Call") {
+			t.Errorf("Expected identical headers, got %s and %s",
+				first.Header().Get("X-Call"), second.Header().Get("X-Call"))
+		}
+	})
+
+	// Test case: replays carry only the headers the handler set
+	t.Run("HandlerHeadersOnly", func(t *testing.T) {
+		store := middleware.NewMemoryIdempotencyStore(time.Minute)
+		session := httpx.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) error {
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "secret"})
+			w.Header().Set("X-Call", "1")
+			return httpx.JSON(w, "created", http.StatusCreated)
+		})
+
+		var requests atomic.Int32
+		wrapped := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Request-ID", "req-"+strconv.Itoa(int(requests.Add(1))))
+			middleware.Idempotency(store)(session).ServeHTTP(w, r)
+		})
+
+		for range 2 {
+			req := httptest.NewRequest(http.MethodPost, "/payments", nil)
+			req.Header.Set(middleware.IdempotencyKeyHeader, "key-1")
+			wrapped.ServeHTTP(httptest.NewRecorder(), req)
+		}
+
+		req := httptest.NewRequest(http.MethodPost, "/payments", nil)
+		req.Header.Set(middleware.IdempotencyKeyHeader, "key-1")
+		w := httptest.NewRecorder()
+		wrapped.ServeHTTP(w, req)
+
+		if w.Header().Get("X-Call") != "1" {
+			t.Errorf("Expected X-Call '1', got '%s'", w.Header().Get("X-Call"))
+		}
+		if w.Header().Get("X-Request-ID") != "req-3" {
+			t.Errorf("Expected X-Request-ID 'req-3', got '%s'", w.Header().Get("X-Request-ID"))
+		}
+		if w.Header().Get("Set-Cookie") != "" {
+			t.Errorf("Expected no Set-Cookie on replay, got '%s'", w.Header().Get("Set-Cookie"))
+		}
+	})
+
+	// Test case: a key function scopes keys per caller
+	t.Run("KeyFunc", func(t *testing.T) {
+		calls.Store(0)
+		wrapped := middleware.Idempotency(middleware.NewMemoryIdempotencyStore(time.Minute),
+			middleware.WithIdempotencyKeyFunc(func(r *http.Request, key string) string {
+				return r.Header.Get("X-User") + " " + key
+			}))(handler)
+
+		for _, user := range []string{"alice", "bob", "alice"} {
+			req := httptest.NewRequest(http.MethodPost, "/payments", nil)
+			req.Header.Set(middleware.IdempotencyKeyHeader, "key-1")
+			req.Header.Set("X-User", user)
+			wrapped.ServeHTTP(httptest.NewRecorder(), req)
+		}
+
+		if calls.Load() != 2 {
+			t.Errorf("Expected handler to run twice, ran %d times", calls.Load())
+		}
+	})
+
+	// Test case: requests without a key are not deduplicated
+	t.Run("NoKey", func(t *testing.T) {
+		calls.Store(0)
+		wrapped := middleware.Idempotency(middleware.NewMemoryIdempotencyStore(time.Minute))(handler)
+
+		for range 2 {
+			req := httptest.NewRequest(http.MethodPost, "/payments", nil)
+			wrapped.ServeHTTP(httptest.NewRecorder(), req)
+		}
+
+		if calls.Load() != 2 {
+			t.Errorf("Expected handler to run twice, ran %d times", calls.Load())
+		}
+	})
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/vibe-go/vibe/httpx"
+)
+
+// RequestLineOption configures the LimitRequestLine middleware.
+type RequestLineOption func(*requestLineConfig)
+
+// requestLineConfig holds the configuration for the LimitRequestLine middleware.
+type requestLineConfig struct {
+	maxHeaders int
+}
+
+// WithMaxHeaders also rejects requests carrying more than n header fields
+// with 431 Request Header Fields Too Large. Repeated fields count once per
+// value.
+func WithMaxHeaders(n int) RequestLineOption {
+	return func(c *requestLineConfig) {
+		c.maxHeaders = n
+	}
+}
+
+// LimitRequestLine returns a middleware that rejects requests whose URL,
+// path and query included, is longer than maxURLLen bytes with 414 URI Too
+// Long. It is a lightweight guard for deployments whose fronting server
+// does not enforce such limits itself.
+//
+// Example:
+//
+//	router.Use(middleware.LimitRequestLine(8192, middleware.WithMaxHeaders(100)))
+func LimitRequestLine(maxURLLen int, options ...RequestLineOption) func(next http.Handler) http.Handler {
+	cfg := &requestLineConfig{}
+	for _, option := range options {
+		option(cfg)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return httpx.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			uri := r.RequestURI
+			if uri == "" {
+				uri = r.URL.RequestURI()
+			}
+			if len(uri) > maxURLLen {
+				return httpx.Error(w, fmt.Errorf("request URI exceeds %d bytes", maxURLLen),
+					http.StatusRequestURITooLong)
+			}
+
+			if cfg.maxHeaders > 0 {
+				count := 0
+				for _, values := range r.Header {
+					count += len(values)
+				}
+				if count > cfg.maxHeaders {
+					return httpx.Error(w, errors.New("too many request header fields"),
+						http.StatusRequestHeaderFieldsTooLarge)
+				}
+			}
+
+			next.ServeHTTP(w, r)
+			return nil
+		})
+	}
+}
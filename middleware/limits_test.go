@@ -0,0 +1,58 @@
+package middleware_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/vibe-go/vibe/middleware"
+)
+
+func TestLimitRequestLine(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	wrapped := middleware.LimitRequestLine(64, middleware.WithMaxHeaders(5))(handler)
+
+	// Test case: URLs within the limit pass
+	t.Run("WithinLimit", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/items?page=2", nil)
+		w := httptest.NewRecorder()
+
+		wrapped.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+		}
+	})
+
+	// Test case: a long query string is rejected
+	t.Run("URLTooLong", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/items?q="+strings.Repeat("a", 64), nil)
+		w := httptest.NewRecorder()
+
+		wrapped.ServeHTTP(w, req)
+
+		if w.Code != http.StatusRequestURITooLong {
+			t.Errorf("Expected status code %d, got %d", http.StatusRequestURITooLong, w.Code)
+		}
+	})
+
+	// Test case: too many header fields are rejected
+	t.Run("TooManyHeaders", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		for i := 0; i < 6; i++ {
+			req.Header.Add(fmt.Sprintf("X-Field-%d", i), "v")
+		}
+		w := httptest.NewRecorder()
+
+		wrapped.ServeHTTP(w, req)
+
+		if w.Code != http.StatusRequestHeaderFieldsTooLarge {
+			t.Errorf("Expected status code %d, got %d", http.StatusRequestHeaderFieldsTooLarge, w.Code)
+		}
+	})
+}
@@ -6,56 +6,428 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/vibe-go/vibe/httpx"
 )
 
-func WithTimeout(timeout time.Duration) func(next http.Handler) http.Handler {
+// TimeoutOption configures the timeout middleware.
+type TimeoutOption func(*timeoutConfig)
+
+// timeoutConfig holds the configuration for the timeout middleware.
+type timeoutConfig struct {
+	status    int
+	message   string
+	responder httpx.ErrorResponder
+	header    bool
+}
+
+// WithTimeoutStatus sets the status code written when a request times out.
+// The default is 408 Request Timeout; 504 Gateway Timeout is a common
+// alternative when the delay is caused by an upstream dependency.
+func WithTimeoutStatus(status int) TimeoutOption {
+	return func(c *timeoutConfig) {
+		c.status = status
+	}
+}
+
+// WithTimeoutMessage sets the error message written when a request times out.
+// The default is "request timed out".
+func WithTimeoutMessage(message string) TimeoutOption {
+	return func(c *timeoutConfig) {
+		c.message = message
+	}
+}
+
+// WithTimeoutResponder sets the ErrorResponder used to write the timeout
+// response, e.g. to render an HTML or plain-text page instead of JSON.
+// By default the package-level default responder is used.
+func WithTimeoutResponder(responder httpx.ErrorResponder) TimeoutOption {
+	return func(c *timeoutConfig) {
+		c.responder = responder
+	}
+}
+
+// TimeoutHeader is the response header set by WithTimeoutHeader.
+const TimeoutHeader = "X-Timeout-Ms"
+
+// WithTimeoutHeader makes the timeout middleware report the time budget
+// applied to the request, in milliseconds, in the X-Timeout-Ms response
+// header. If the request already carried an earlier deadline, that shorter
+// budget is reported, since it is the one in effect.
+func WithTimeoutHeader() TimeoutOption {
+	return func(c *timeoutConfig) {
+		c.header = true
+	}
+}
+
+// WithTimeout returns a middleware that aborts requests taking longer than
+// timeout, responding with an error in the default error format.
+//
+// Headers set by the handler are held back until it writes the status or
+// body. If the deadline passes first, they are discarded and a clean error
+// response is written; anything the handler writes afterwards is dropped.
+// If the handler had already started the response, it is left as is, except
+// that a write still blocked on a slow client is interrupted.
+// A panic in the handler is re-raised on the serving goroutine, so that a
+// Recovery middleware wrapping WithTimeout handles it.
+// If the client cancels the request before the deadline, nothing is written.
+func WithTimeout(timeout time.Duration, options ...TimeoutOption) func(next http.Handler) http.Handler {
+	cfg := &timeoutConfig{
+		status:  http.StatusRequestTimeout,
+		message: "request timed out",
+	}
+
+	for _, option := range options {
+		option(cfg)
+	}
+
 	return func(next http.Handler) http.Handler {
 		return httpx.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			if cfg.header {
+				effective := timeout
+				if deadline, ok := r.Context().Deadline(); ok {
+					effective = min(effective, time.Until(deadline))
+				}
+				w.Header().Set(TimeoutHeader, strconv.FormatInt(effective.Milliseconds(), 10))
+			}
+
 			ctx, cancel := context.WithTimeout(r.Context(), timeout)
 			defer cancel()
 
 			r = r.WithContext(ctx)
 
-			done := make(chan struct{}, 1)
-			var err error
-
-			go func() {
-				defer func() {
-					done <- struct{}{}
-				}()
-
-				respCapturer := NewResponseCapturer(w)
-				next.ServeHTTP(respCapturer, r)
-
-				if respCapturer.Error() != nil {
-					err = respCapturer.Error()
-				}
-			}()
+			tw := newTimeoutWriter(w)
+			go tw.serve(next, r)
 
 			select {
-			case <-done:
-				if err != nil {
-					return err
+			case <-tw.done:
+				// Panic on this goroutine, where an enclosing Recovery
+				// middleware can recover it.
+				if tw.panicked != nil {
+					panic(tw.panicked)
+				}
+				tw.finish()
+				// Errors from a response the handler has already written
+				// cannot be reported to the client again.
+				if tw.err != nil && !httpx.Written(w) {
+					return tw.err
 				}
 				return nil
 			case <-ctx.Done():
-				return httpx.Error(w, errors.New("request timed out"), http.StatusRequestTimeout)
+				// Fail any body read the handler is stuck in, e.g. on a
+				// stalled upload, so that its goroutine can return.
+				_ = http.NewResponseController(w).SetReadDeadline(time.Now())
+				tw.timeout(w)
+				// A canceled context means the client went away; there is
+				// no one left to send the timeout response to.
+				if errors.Is(ctx.Err(), context.Canceled) {
+					return httpx.ErrAbort
+				}
+				if cfg.responder != nil && !httpx.Written(w) {
+					return cfg.responder.Error(w, r, errors.New(cfg.message), cfg.status)
+				}
+				return httpx.Error(w, errors.New(cfg.message), cfg.status)
 			}
 		})
 	}
 }
 
+// timeoutWriter is the ResponseWriter handed to handlers by WithTimeout.
+// It buffers headers until the response starts and serializes writes with
+// the timeout path, so that nothing reaches the client after a timeout.
+// Like ResponseCapturer, it records write errors and error statuses.
+// Body writes happen outside mu, so that a timeout is never held up by a
+// slow client; writes tracks them so the timeout path can wait them out.
+type timeoutWriter struct {
+	w        http.ResponseWriter
+	done     chan struct{}
+	mu       sync.Mutex
+	writes   sync.WaitGroup
+	active   int         // writes in progress
+	header   http.Header // copied from w on first use
+	err      error
+	panicked any
+	started  bool
+	timedOut bool
+}
+
+// newTimeoutWriter returns a timeoutWriter for w.
+func newTimeoutWriter(w http.ResponseWriter) *timeoutWriter {
+	return &timeoutWriter{w: w, done: make(chan struct{}, 1)}
+}
+
+// serve runs next and signals done when it returns. A panic in next is
+// recorded in panicked instead of crashing the process, as nothing else
+// recovers on this goroutine.
+func (tw *timeoutWriter) serve(next http.Handler, r *http.Request) {
+	defer func() {
+		tw.panicked = recover()
+		tw.done <- struct{}{}
+	}()
+	next.ServeHTTP(tw, r)
+}
+
+// Header returns the buffered header map. The handler starts with a copy of
+// the headers already set on the underlying writer.
+func (tw *timeoutWriter) Header() http.Header {
+	if tw.header != nil {
+		return tw.header
+	}
+
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	// After a timeout the underlying headers belong to the error response.
+	if tw.timedOut {
+		tw.header = http.Header{}
+	} else {
+		tw.header = tw.w.Header().Clone()
+	}
+	return tw.header
+}
+
+// WriteHeader sends the buffered headers and the status code, unless the
+// request has timed out.
+func (tw *timeoutWriter) WriteHeader(statusCode int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut || tw.started {
+		return
+	}
+	if statusCode >= http.StatusBadRequest {
+		tw.err = fmt.Errorf("response status code: %d", statusCode)
+	}
+	tw.copyHeaders()
+	tw.started = true
+	tw.w.WriteHeader(statusCode)
+}
+
+// Write writes the body, or returns http.ErrHandlerTimeout once the request
+// has timed out.
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	if !tw.begin() {
+		return 0, http.ErrHandlerTimeout
+	}
+	defer tw.end()
+
+	n, err := tw.w.Write(b)
+	if err != nil {
+		tw.mu.Lock()
+		tw.err = err
+		tw.mu.Unlock()
+	}
+	return n, err
+}
+
+// Flush implements http.Flusher if the underlying writer supports it.
+func (tw *timeoutWriter) Flush() {
+	if !tw.begin() {
+		return
+	}
+	defer tw.end()
+
+	if f, ok := tw.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// begin starts the response with an implicit 200 status if needed and
+// registers a write, unless the request has timed out. The caller must
+// call end once the write is over.
+func (tw *timeoutWriter) begin() bool {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.timedOut {
+		return false
+	}
+	if !tw.started {
+		tw.copyHeaders()
+		tw.started = true
+		tw.w.WriteHeader(http.StatusOK)
+	}
+	tw.active++
+	tw.writes.Add(1)
+	return true
+}
+
+// end unregisters a write registered by begin.
+func (tw *timeoutWriter) end() {
+	tw.mu.Lock()
+	tw.active--
+	tw.mu.Unlock()
+	tw.writes.Done()
+}
+
+// Unwrap returns the underlying http.ResponseWriter.
+// It allows http.ResponseController to reach the original writer.
+func (tw *timeoutWriter) Unwrap() http.ResponseWriter {
+	return tw.w
+}
+
+// copyHeaders replaces the headers of the underlying writer with the
+// buffered ones, if the handler used them. The caller must hold tw.mu.
+func (tw *timeoutWriter) copyHeaders() {
+	if tw.header == nil {
+		return
+	}
+	dst := tw.w.Header()
+	clear(dst)
+	for key, values := range tw.header {
+		dst[key] = values
+	}
+}
+
+// finish sends the buffered headers of a handler that completed without
+// writing anything.
+func (tw *timeoutWriter) finish() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if !tw.started {
+		tw.copyHeaders()
+	}
+}
+
+// timeout marks the request as timed out, so that later writes by the
+// handler are dropped. Writes still in progress on w, e.g. blocked on a
+// slow client, are interrupted and waited for, since w must not be used
+// once the middleware returns.
+func (tw *timeoutWriter) timeout(w http.ResponseWriter) {
+	tw.mu.Lock()
+	tw.timedOut = true
+	active := tw.active
+	tw.mu.Unlock()
+
+	if active > 0 {
+		_ = http.NewResponseController(w).SetWriteDeadline(time.Now())
+	}
+	tw.writes.Wait()
+}
+
+// Responder returns a middleware that makes responder the ErrorResponder for
+// all requests passing through it, overriding the default responder. It is
+// typically attached to a route group to give it its own error format.
+//
+// Example:
+//
+//	api := router.Group("/api", middleware.Responder(problemResponder{}))
+func Responder(responder httpx.ErrorResponder) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(httpx.ContextWithResponder(r.Context(), responder)))
+		})
+	}
+}
+
+// Transform returns a middleware that passes the JSON responses of all
+// requests through it to transformer, e.g. httpx.Envelope. It is opt-in and
+// can be attached to the router or to a single group.
+//
+// Example:
+//
+//	api := router.Group("/api", middleware.Transform(httpx.Envelope))
+func Transform(transformer httpx.ResponseTransformer) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(httpx.ContextWithTransformer(r.Context(), transformer)))
+		})
+	}
+}
+
+// EmptyCollections returns a middleware that makes JSON responses render
+// nil slices and maps at the top level as [] and {} instead of null; see
+// httpx.EmptyCollections. It can be attached to the router or to a single
+// group.
+//
+// Example:
+//
+//	router.Use(middleware.EmptyCollections())
+func EmptyCollections() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(httpx.ContextWithEmptyCollections(r.Context())))
+		})
+	}
+}
+
+// internalPanicError returns the error reported for a panic answered with
+// a 500: the localized internal error message and, when there is a request
+// ID, a panicError carrying it, so that the JSON error responder adds it to
+// the body for users to quote when reporting the failure.
+func internalPanicError(r *http.Request, err error, id string) error {
+	err = fmt.Errorf("%s: %w", httpx.Message(r, httpx.MessageInternalError), err)
+	if id != "" {
+		return &panicError{err: err, id: id}
+	}
+	return err
+}
+
+// panicError is a recovered panic error tagged with the request ID.
+type panicError struct {
+	err error
+	id  string
+}
+
+// Error returns the message of the wrapped error.
+func (e *panicError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (e *panicError) Unwrap() error {
+	return e.err
+}
+
+// RequestID returns the ID of the request that panicked.
+func (e *panicError) RequestID() string {
+	return e.id
+}
+
+// RecoveryOption configures the recovery middleware.
+type RecoveryOption func(*recoveryConfig)
+
+// recoveryConfig holds the configuration for the recovery middleware.
+type recoveryConfig struct {
+	responder httpx.ErrorResponder
+}
+
+// WithRecoveryResponder sets the ErrorResponder used to write the 500
+// response after a panic, e.g. to render a styled HTML error page.
+// By default a JSON error is written.
+func WithRecoveryResponder(responder httpx.ErrorResponder) RecoveryOption {
+	return func(c *recoveryConfig) {
+		c.responder = responder
+	}
+}
+
 // Recovery returns a middleware that recovers from panics and logs the error.
 // It takes a logger to record panic information.
-func Recovery(logger *log.Logger) func(next http.Handler) http.Handler {
+// The response is a 500 Internal Server Error, unless the panic value is an
+// error carrying its own status: one with a StatusCode() int method, or an
+// *httpx.StatusError.
+// If the RequestID middleware ran before it, the request ID is included in
+// the log line and, as a "request_id" field, in the default JSON error
+// response. The error passed to a custom responder has a RequestID() string
+// method returning it.
+func Recovery(logger *log.Logger, options ...RecoveryOption) func(next http.Handler) http.Handler {
 	// Use a default logger if none is provided
 	if logger == nil {
 		logger = log.New(log.Writer(), "[recovery] ", log.LstdFlags)
 	}
 
+	cfg := &recoveryConfig{}
+	for _, option := range options {
+		option(cfg)
+	}
+
 	return func(next http.Handler) http.Handler {
 		return httpx.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
 			defer func() {
@@ -64,8 +436,25 @@ func Recovery(logger *log.Logger) func(next http.Handler) http.Handler {
 					if !ok {
 						err = fmt.Errorf("%v", rec)
 					}
-					logger.Printf("recovered from panic: %v", err)
-					err = httpx.InternalError(w, err)
+					r = r.WithContext(context.WithValue(r.Context(), recoveredKey{}, sanitizePanic(err)))
+					id := RequestIDFrom(r.Context())
+					if id == "" {
+						logger.Printf("recovered from panic: %v", err)
+					} else {
+						logger.Printf("recovered from panic (request %s): %v", id, err)
+					}
+
+					status := panicStatus(err)
+					switch {
+					case status != http.StatusInternalServerError && cfg.responder != nil && !httpx.Written(w):
+						err = cfg.responder.Error(w, r, err, status)
+					case status != http.StatusInternalServerError:
+						err = httpx.Error(w, err, status)
+					case cfg.responder != nil && !httpx.Written(w):
+						err = cfg.responder.Error(w, r, internalPanicError(r, err, id), http.StatusInternalServerError)
+					default:
+						err = httpx.Error(w, internalPanicError(r, err, id), http.StatusInternalServerError)
+					}
 					if err != nil {
 						logger.Printf("failed to write error response: %v", err)
 					}
@@ -77,7 +466,65 @@ func Recovery(logger *log.Logger) func(next http.Handler) http.Handler {
 	}
 }
 
-// Logger returns a middleware that logs each request with method, path, and duration.
+// panicStatus returns the status a recovered error asks to be reported
+// with, or 500 if it does not carry one.
+func panicStatus(err error) int {
+	var coded interface{ StatusCode() int }
+	if errors.As(err, &coded) {
+		if status := coded.StatusCode(); status >= http.StatusBadRequest && status <= 599 {
+			return status
+		}
+	}
+	var statusErr *httpx.StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Status
+	}
+	return http.StatusInternalServerError
+}
+
+// recoveredKey is the context key for the value recovered by Recovery.
+type recoveredKey struct{}
+
+// maxRecoveredLen is the maximum length of the value kept by RecoveredValue.
+const maxRecoveredLen = 256
+
+// RecoveredValue returns a description of the panic recovered by Recovery,
+// for use by the responder set with WithRecoveryResponder, e.g. to show on a
+// custom error page. It is the panic value's text limited to 256 bytes with
+// control characters removed, and ok is false if there was no panic.
+//
+// Example:
+//
+//	func (errorPage) Error(w http.ResponseWriter, r *http.Request, err error, status int) error {
+//		detail, _ := middleware.RecoveredValue(r.Context())
+//		return renderErrorPage(w, status, detail)
+//	}
+func RecoveredValue(ctx context.Context) (string, bool) {
+	value, ok := ctx.Value(recoveredKey{}).(string)
+	return value, ok
+}
+
+// sanitizePanic returns the text of err for RecoveredValue.
+func sanitizePanic(err error) string {
+	value := strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, err.Error())
+	if len(value) <= maxRecoveredLen {
+		return value
+	}
+	value = value[:maxRecoveredLen]
+	// Do not cut a multi-byte character in half.
+	for !utf8.ValidString(value) {
+		value = value[:len(value)-1]
+	}
+	return value
+}
+
+// Logger returns a middleware that logs each request with method, path, duration,
+// status and response size.
 func Logger(logger *log.Logger) func(next http.Handler) http.Handler {
 	if logger == nil {
 		logger = log.New(log.Writer(), "[http] ", log.LstdFlags)
@@ -88,23 +535,116 @@ func Logger(logger *log.Logger) func(next http.Handler) http.Handler {
 			start := time.Now()
 			logger.Printf("Request: %s %s", r.Method, r.URL.Path)
 
-			next.ServeHTTP(w, r)
+			rw := httpx.NewResponseWriter(w)
+			next.ServeHTTP(rw, r)
 
-			logger.Printf("Completed: %s %s in %v", r.Method, r.URL.Path, time.Since(start))
+			status := rw.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			logger.Printf("Completed: %s %s in %v (%d, %d bytes)",
+				r.Method, r.URL.Path, time.Since(start), status, rw.Size())
 			return nil
 		})
 	}
 }
 
-// ResponseCapturer is a wrapper for http.ResponseWriter that captures errors.
+// loggerKey is the context key for the request-scoped logger.
+type loggerKey struct{}
+
+// WithLogger returns a middleware that stores a request-scoped logger in the
+// request context, retrievable with LoggerFromContext. The logger writes to
+// base with base's flags, and its prefix is extended with the request ID
+// (when the RequestID middleware ran before it) and the request path.
+func WithLogger(base *log.Logger) func(next http.Handler) http.Handler {
+	if base == nil {
+		base = log.Default()
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			prefix := base.Prefix()
+			if id := RequestIDFrom(r.Context()); id != "" {
+				prefix += "[" + id + "] "
+			}
+			prefix += r.Method + " " + r.URL.Path + " "
+
+			logger := log.New(base.Writer(), prefix, base.Flags())
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), loggerKey{}, logger)))
+		})
+	}
+}
+
+// LoggerFromContext returns the request-scoped logger stored by WithLogger,
+// or the standard logger if the middleware did not run.
+func LoggerFromContext(r *http.Request) *log.Logger {
+	if logger, ok := r.Context().Value(loggerKey{}).(*log.Logger); ok {
+		return logger
+	}
+	return log.Default()
+}
+
+// SlowLog returns a middleware that logs only requests taking longer than
+// threshold, with their method, path, status and duration.
+func SlowLog(threshold time.Duration, logger *log.Logger) func(next http.Handler) http.Handler {
+	if logger == nil {
+		logger = log.New(log.Writer(), "[slow] ", log.LstdFlags)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return httpx.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			start := time.Now()
+			rw := httpx.NewResponseWriter(w)
+
+			next.ServeHTTP(rw, r)
+
+			if elapsed := time.Since(start); elapsed > threshold {
+				status := rw.Status()
+				if status == 0 {
+					status = http.StatusOK
+				}
+				logger.Printf("Slow request: %s %s %d in %v", r.Method, r.URL.Path, status, elapsed)
+			}
+			return nil
+		})
+	}
+}
+
+// ResponseCapturer is a wrapper for http.ResponseWriter that captures the
+// response status and errors. It can be reused by custom middleware that
+// needs to know how the handler responded.
 type ResponseCapturer struct {
 	http.ResponseWriter
-	Err error
+	Err           error
+	status        int
+	captureErrors bool
+}
+
+// CapturerOption configures a ResponseCapturer.
+type CapturerOption func(*ResponseCapturer)
+
+// WithCaptureErrors sets whether error statuses (4xx and 5xx) are recorded
+// as errors. It is on by default; turn it off to only observe the status.
+// Write errors are always recorded.
+func WithCaptureErrors(capture bool) CapturerOption {
+	return func(r *ResponseCapturer) {
+		r.captureErrors = capture
+	}
 }
 
 // NewResponseCapturer creates a new response capturer that wraps a ResponseWriter.
-func NewResponseCapturer(w http.ResponseWriter) *ResponseCapturer {
-	return &ResponseCapturer{ResponseWriter: w}
+//
+// Example:
+//
+//	capturer := middleware.NewResponseCapturer(w, middleware.WithCaptureErrors(false))
+//	next.ServeHTTP(capturer, r)
+//	metrics.Observe(r.URL.Path, capturer.StatusCode())
+func NewResponseCapturer(w http.ResponseWriter, options ...CapturerOption) *ResponseCapturer {
+	r := &ResponseCapturer{ResponseWriter: w, captureErrors: true}
+	for _, option := range options {
+		option(r)
+	}
+	return r
 }
 
 func (r *ResponseCapturer) setError(err error) {
@@ -113,6 +653,9 @@ func (r *ResponseCapturer) setError(err error) {
 
 // Write overrides the underlying ResponseWriter's Write method to capture errors.
 func (r *ResponseCapturer) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
 	n, err := r.ResponseWriter.Write(b)
 	if err != nil {
 		r.setError(err)
@@ -122,14 +665,27 @@ func (r *ResponseCapturer) Write(b []byte) (int, error) {
 
 // WriteHeader overrides the underlying ResponseWriter's WriteHeader method.
 func (r *ResponseCapturer) WriteHeader(statusCode int) {
-	// Optionally capture non-2xx status codes as errors
-	if statusCode >= http.StatusBadRequest {
+	if r.status == 0 {
+		r.status = statusCode
+	}
+	if r.captureErrors && statusCode >= http.StatusBadRequest {
 		r.setError(fmt.Errorf("response status code: %d", statusCode))
 	}
 	r.ResponseWriter.WriteHeader(statusCode)
 }
 
+// StatusCode returns the status code of the response, or 0 if nothing has
+// been written yet.
+func (r *ResponseCapturer) StatusCode() int {
+	return r.status
+}
+
 // Error returns the captured error.
 func (r *ResponseCapturer) Error() error {
 	return r.Err
 }
+
+// Unwrap returns the underlying http.ResponseWriter.
+func (r *ResponseCapturer) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -2,14 +2,17 @@ package middleware_test
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"log"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"sync"
 	"testing"
 	"time"
+	"unicode/utf8"
 
 	"github.com/vibe-go/vibe/httpx"
 	"github.com/vibe-go/vibe/middleware"
@@ -38,6 +41,29 @@ func TestWithTimeout(t *testing.T) {
 		}
 	})
 
+	// Test case: handler writes a non-200 success status before timeout
+	t.Run("PropagatesSuccessStatus", func(t *testing.T) {
+		handler := httpx.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) error {
+			return httpx.JSON(w, map[string]string{"id": "1"}, http.StatusCreated)
+		})
+
+		wrapped := middleware.WithTimeout(100 * time.Millisecond)(handler)
+
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		w := httptest.NewRecorder()
+
+		wrapped.ServeHTTP(w, req)
+
+		resp := w.Result()
+		if resp.StatusCode != http.StatusCreated {
+			t.Errorf("Expected status code %d, got %d", http.StatusCreated, resp.StatusCode)
+		}
+
+		if strings.TrimSpace(w.Body.String()) != `{"id":"1"}` {
+			t.Errorf("Expected body %s, got %s", `{"id":"1"}`, w.Body.String())
+		}
+	})
+
 	// Test case: handler times out
 	t.Run("TimesOut", func(t *testing.T) {
 		handler := httpx.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) error {
@@ -59,6 +85,112 @@ func TestWithTimeout(t *testing.T) {
 		}
 	})
 
+	// Test case: headers set before the timeout are discarded
+	t.Run("TimesOutDiscardsHeaders", func(t *testing.T) {
+		handler := httpx.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) error {
+			w.Header().Set("X-Partial", "yes")
+			time.Sleep(100 * time.Millisecond)
+			w.WriteHeader(http.StatusOK)
+			return nil
+		})
+
+		wrapped := middleware.WithTimeout(50 * time.Millisecond)(handler)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		w := httptest.NewRecorder()
+
+		wrapped.ServeHTTP(w, req)
+
+		resp := w.Result()
+		if resp.StatusCode != http.StatusRequestTimeout {
+			t.Errorf("Expected status code %d, got %d", http.StatusRequestTimeout, resp.StatusCode)
+		}
+
+		if resp.Header.Get("X-Partial") != "" {
+			t.Errorf("Expected X-Partial header to be discarded, got '%s'", resp.Header.Get("X-Partial"))
+		}
+
+		if !strings.Contains(w.Body.String(), "request timed out") {
+			t.Errorf("Expected JSON error body, got %s", w.Body.String())
+		}
+	})
+
+	// Test case: headers set by a handler that completes in time are sent
+	t.Run("KeepsHeadersWhenInTime", func(t *testing.T) {
+		handler := httpx.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) error {
+			w.Header().Set("X-Partial", "yes")
+			return nil
+		})
+
+		wrapped := middleware.WithTimeout(time.Second)(handler)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		w := httptest.NewRecorder()
+
+		wrapped.ServeHTTP(w, req)
+
+		if w.Header().Get("X-Partial") != "yes" {
+			t.Errorf("Expected X-Partial header 'yes', got '%s'", w.Header().Get("X-Partial"))
+		}
+	})
+
+	// Test case: handler times out with a custom status
+	t.Run("TimesOutWithCustomStatus", func(t *testing.T) {
+		handler := httpx.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) error {
+			time.Sleep(100 * time.Millisecond)
+			w.WriteHeader(http.StatusOK)
+			return nil
+		})
+
+		wrapped := middleware.WithTimeout(50*time.Millisecond,
+			middleware.WithTimeoutStatus(http.StatusGatewayTimeout))(handler)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		w := httptest.NewRecorder()
+
+		wrapped.ServeHTTP(w, req)
+
+		resp := w.Result()
+		if resp.StatusCode != http.StatusGatewayTimeout {
+			t.Errorf("Expected status code %d, got %d", http.StatusGatewayTimeout, resp.StatusCode)
+		}
+
+		if !strings.Contains(w.Body.String(), "request timed out") {
+			t.Errorf("Expected JSON error body, got %s", w.Body.String())
+		}
+	})
+
+	// Test case: handler times out with a custom message and responder
+	t.Run("TimesOutWithCustomResponder", func(t *testing.T) {
+		handler := httpx.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) error {
+			time.Sleep(100 * time.Millisecond)
+			w.WriteHeader(http.StatusOK)
+			return nil
+		})
+
+		wrapped := middleware.WithTimeout(50*time.Millisecond,
+			middleware.WithTimeoutMessage("too slow"),
+			middleware.WithTimeoutResponder(textResponder{}))(handler)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		w := httptest.NewRecorder()
+
+		wrapped.ServeHTTP(w, req)
+
+		resp := w.Result()
+		if resp.StatusCode != http.StatusRequestTimeout {
+			t.Errorf("Expected status code %d, got %d", http.StatusRequestTimeout, resp.StatusCode)
+		}
+
+		if resp.Header.Get("Content-Type") != "text/plain" {
+			t.Errorf("Expected Content-Type 'text/plain', got '%s'", resp.Header.Get("Content-Type"))
+		}
+
+		if w.Body.String() != "error: too slow" {
+			t.Errorf("Expected body 'error: too slow', got '%s'", w.Body.String())
+		}
+	})
+
 	// Test case: handler returns an error
 	t.Run("HandlerReturnsError", func(t *testing.T) {
 		expectedErr := errors.New("handler error")
@@ -107,6 +239,122 @@ func TestWithTimeout(t *testing.T) {
 	})
 }
 
+func TestWithTimeoutPanic(t *testing.T) {
+	handler := httpx.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) error {
+		panic("boom")
+	})
+
+	var buf bytes.Buffer
+	wrapped := middleware.Recovery(log.New(&buf, "", 0))(middleware.WithTimeout(time.Second)(handler))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+
+	wrapped.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status code %d, got %d", http.StatusInternalServerError, w.Code)
+	}
+	if !strings.Contains(buf.String(), "boom") {
+		t.Errorf("Expected Recovery to log the panic, got: %s", buf.String())
+	}
+}
+
+func TestWithTimeoutResponseController(t *testing.T) {
+	var deadlineErr error
+	handler := httpx.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) error {
+		deadlineErr = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(time.Second))
+		w.WriteHeader(http.StatusOK)
+		return nil
+	})
+
+	server := httptest.NewServer(middleware.WithTimeout(time.Second)(handler))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if deadlineErr != nil {
+		t.Errorf("Expected SetWriteDeadline to reach the connection, got %v", deadlineErr)
+	}
+}
+
+func TestWithTimeoutClientCancel(t *testing.T) {
+	handler := httpx.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) error {
+		<-r.Context().Done()
+		return nil
+	})
+
+	wrapped := middleware.WithTimeout(time.Second)(handler)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+	w := httptest.NewRecorder()
+
+	time.AfterFunc(10*time.Millisecond, cancel)
+	wrapped.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || w.Body.Len() != 0 {
+		t.Errorf("Expected no response after client cancel, got status %d and body %q", w.Code, w.Body.String())
+	}
+}
+
+func TestWithTimeoutHeader(t *testing.T) {
+	handler := httpx.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) error {
+		w.WriteHeader(http.StatusOK)
+		return nil
+	})
+
+	// Test case: the header reports the configured timeout
+	t.Run("Configured", func(t *testing.T) {
+		wrapped := middleware.WithTimeout(1500*time.Millisecond, middleware.WithTimeoutHeader())(handler)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		w := httptest.NewRecorder()
+
+		wrapped.ServeHTTP(w, req)
+
+		if got := w.Header().Get(middleware.TimeoutHeader); got != "1500" {
+			t.Errorf("Expected %s '1500', got '%s'", middleware.TimeoutHeader, got)
+		}
+	})
+
+	// Test case: an earlier deadline on the request takes precedence
+	t.Run("EarlierDeadline", func(t *testing.T) {
+		wrapped := middleware.WithTimeout(time.Minute, middleware.WithTimeoutHeader())(handler)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+		w := httptest.NewRecorder()
+
+		wrapped.ServeHTTP(w, req)
+
+		got, err := strconv.Atoi(w.Header().Get(middleware.TimeoutHeader))
+		if err != nil || got <= 0 || got > 2000 {
+			t.Errorf("Expected %s of at most 2000, got '%s'", middleware.TimeoutHeader,
+				w.Header().Get(middleware.TimeoutHeader))
+		}
+	})
+
+	// Test case: the header is not set by default
+	t.Run("Disabled", func(t *testing.T) {
+		wrapped := middleware.WithTimeout(time.Second)(handler)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		w := httptest.NewRecorder()
+
+		wrapped.ServeHTTP(w, req)
+
+		if got := w.Header().Get(middleware.TimeoutHeader); got != "" {
+			t.Errorf("Expected no %s header, got '%s'", middleware.TimeoutHeader, got)
+		}
+	})
+}
+
 func TestRecovery(t *testing.T) {
 	// Test case: no panic
 	t.Run("NoPanic", func(t *testing.T) {
@@ -182,6 +430,131 @@ func TestRecovery(t *testing.T) {
 	})
 }
 
+func TestRecoveryWithResponder(t *testing.T) {
+	handler := httpx.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) error {
+		panic("boom")
+	})
+
+	wrapped := middleware.Recovery(log.New(&bytes.Buffer{}, "", 0),
+		middleware.WithRecoveryResponder(textResponder{}))(handler)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+
+	wrapped.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status code %d, got %d", http.StatusInternalServerError, w.Code)
+	}
+
+	if w.Header().Get("Content-Type") != "text/plain" {
+		t.Errorf("Expected Content-Type 'text/plain', got '%s'", w.Header().Get("Content-Type"))
+	}
+
+	if w.Body.String() != "error: internal server error: boom" {
+		t.Errorf("Expected custom error body, got '%s'", w.Body.String())
+	}
+}
+
+// codedError is an error that carries its HTTP status.
+type codedError struct {
+	status int
+}
+
+func (e codedError) Error() string   { return "invalid cursor" }
+func (e codedError) StatusCode() int { return e.status }
+
+func TestRecoveryPanicStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  interface{}
+		status int
+	}{
+		{"StatusCodeMethod", codedError{status: http.StatusBadRequest}, http.StatusBadRequest},
+		{"StatusError", httpx.NewStatusError(http.StatusConflict, errors.New("conflict")), http.StatusConflict},
+		{"PlainError", errors.New("boom"), http.StatusInternalServerError},
+		{"String", "boom", http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := httpx.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) error {
+				panic(tt.value)
+			})
+			wrapped := middleware.Recovery(log.New(&bytes.Buffer{}, "", 0))(handler)
+
+			w := httptest.NewRecorder()
+			wrapped.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if w.Code != tt.status {
+				t.Errorf("Expected status code %d, got %d", tt.status, w.Code)
+			}
+		})
+	}
+
+	t.Run("Body", func(t *testing.T) {
+		handler := httpx.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) error {
+			panic(codedError{status: http.StatusBadRequest})
+		})
+		wrapped := middleware.Recovery(log.New(&bytes.Buffer{}, "", 0))(handler)
+
+		w := httptest.NewRecorder()
+		wrapped.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		if strings.TrimSpace(w.Body.String()) != `{"error":"invalid cursor"}` {
+			t.Errorf("Expected the error message in the body, got %s", w.Body.String())
+		}
+	})
+}
+
+// recoveredResponder records the value exposed by RecoveredValue.
+type recoveredResponder struct {
+	value *string
+}
+
+func (rr recoveredResponder) Error(w http.ResponseWriter, r *http.Request, _ error, status int) error {
+	*rr.value, _ = middleware.RecoveredValue(r.Context())
+	w.WriteHeader(status)
+	return nil
+}
+
+func TestRecoveredValue(t *testing.T) {
+	panicWith := func(value interface{}) string {
+		handler := httpx.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) error {
+			panic(value)
+		})
+
+		var seen string
+		wrapped := middleware.Recovery(log.New(&bytes.Buffer{}, "", 0),
+			middleware.WithRecoveryResponder(recoveredResponder{&seen}))(handler)
+
+		wrapped.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		return seen
+	}
+
+	t.Run("Panic value", func(t *testing.T) {
+		if seen := panicWith("database unavailable"); seen != "database unavailable" {
+			t.Errorf("Expected recovered value 'database unavailable', got %q", seen)
+		}
+	})
+
+	t.Run("Sanitized", func(t *testing.T) {
+		seen := panicWith("line1\nline2\x00" + strings.Repeat("é", 200))
+		if strings.ContainsAny(seen, "\n\x00") {
+			t.Errorf("Expected control characters to be removed, got %q", seen)
+		}
+		if len(seen) > 256 || !utf8.ValidString(seen) {
+			t.Errorf("Expected at most 256 bytes of valid UTF-8, got %d bytes", len(seen))
+		}
+	})
+
+	t.Run("No panic", func(t *testing.T) {
+		if _, ok := middleware.RecoveredValue(context.Background()); ok {
+			t.Error("Expected no recovered value without a panic")
+		}
+	})
+}
+
 func TestLogger(t *testing.T) {
 	// Test case: with default logger
 	t.Run("DefaultLogger", func(t *testing.T) {
@@ -231,6 +604,76 @@ func TestLogger(t *testing.T) {
 		if !strings.Contains(logOutput, "Completed: GET /custom-path") {
 			t.Errorf("Expected log to contain completion info, got: %s", logOutput)
 		}
+		if !strings.Contains(logOutput, "(200, 0 bytes)") {
+			t.Errorf("Expected log to contain status and size, got: %s", logOutput)
+		}
+	})
+}
+
+func TestWithLogger(t *testing.T) {
+	handler := httpx.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		middleware.LoggerFromContext(r).Printf("loading order")
+		w.WriteHeader(http.StatusOK)
+		return nil
+	})
+
+	var buf bytes.Buffer
+	base := log.New(&buf, "[app] ", 0)
+	wrapped := middleware.RequestID()(middleware.WithLogger(base)(handler))
+
+	req := httptest.NewRequest(http.MethodGet, "/orders/1", nil)
+	req.Header.Set(middleware.RequestIDHeader, "req-7")
+	w := httptest.NewRecorder()
+
+	wrapped.ServeHTTP(w, req)
+
+	expected := "[app] [req-7] GET /orders/1 loading order"
+	if !strings.Contains(buf.String(), expected) {
+		t.Errorf("Expected log to contain '%s', got: %s", expected, buf.String())
+	}
+}
+
+func TestSlowLog(t *testing.T) {
+	// Test case: slow request is logged
+	t.Run("Slow", func(t *testing.T) {
+		handler := httpx.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) error {
+			time.Sleep(30 * time.Millisecond)
+			w.WriteHeader(http.StatusAccepted)
+			return nil
+		})
+
+		var buf bytes.Buffer
+		wrapped := middleware.SlowLog(10*time.Millisecond, log.New(&buf, "", 0))(handler)
+
+		req := httptest.NewRequest(http.MethodGet, "/slow", nil)
+		w := httptest.NewRecorder()
+
+		wrapped.ServeHTTP(w, req)
+
+		logOutput := buf.String()
+		if !strings.Contains(logOutput, "Slow request: GET /slow 202") {
+			t.Errorf("Expected log to contain slow request info, got: %s", logOutput)
+		}
+	})
+
+	// Test case: fast request is not logged
+	t.Run("Fast", func(t *testing.T) {
+		handler := httpx.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) error {
+			w.WriteHeader(http.StatusOK)
+			return nil
+		})
+
+		var buf bytes.Buffer
+		wrapped := middleware.SlowLog(time.Second, log.New(&buf, "", 0))(handler)
+
+		req := httptest.NewRequest(http.MethodGet, "/fast", nil)
+		w := httptest.NewRecorder()
+
+		wrapped.ServeHTTP(w, req)
+
+		if buf.Len() != 0 {
+			t.Errorf("Expected no log output, got: %s", buf.String())
+		}
 	})
 }
 
@@ -303,6 +746,56 @@ func TestResponseCapturer(t *testing.T) {
 			t.Errorf("Expected capturer to store 'write error', got: %v", capturer.Error())
 		}
 	})
+
+	// Test status capture with and without error semantics
+	t.Run("StatusCode", func(t *testing.T) {
+		tests := []struct {
+			name      string
+			options   []middleware.CapturerOption
+			expectErr bool
+		}{
+			{"CaptureErrors", nil, true},
+			{"StatusOnly", []middleware.CapturerOption{middleware.WithCaptureErrors(false)}, false},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				capturer := middleware.NewResponseCapturer(httptest.NewRecorder(), tt.options...)
+
+				capturer.WriteHeader(http.StatusNotFound)
+
+				if capturer.StatusCode() != http.StatusNotFound {
+					t.Errorf("Expected status code %d, got %d", http.StatusNotFound, capturer.StatusCode())
+				}
+				if (capturer.Error() != nil) != tt.expectErr {
+					t.Errorf("Expected error: %v, got %v", tt.expectErr, capturer.Error())
+				}
+			})
+		}
+	})
+
+	// Test implicit status on Write
+	t.Run("ImplicitStatus", func(t *testing.T) {
+		capturer := middleware.NewResponseCapturer(httptest.NewRecorder())
+
+		if capturer.StatusCode() != 0 {
+			t.Errorf("Expected status code 0 before writing, got %d", capturer.StatusCode())
+		}
+		capturer.Write([]byte("ok"))
+		if capturer.StatusCode() != http.StatusOK {
+			t.Errorf("Expected status code %d, got %d", http.StatusOK, capturer.StatusCode())
+		}
+	})
+}
+
+// textResponder is an ErrorResponder that writes plain-text errors.
+type textResponder struct{}
+
+func (textResponder) Error(w http.ResponseWriter, _ *http.Request, err error, status int) error {
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(status)
+	_, werr := w.Write([]byte("error: " + err.Error()))
+	return werr
 }
 
 // and returns an error on Write.
@@ -321,3 +814,92 @@ func (e *errorResponseWriter) Write([]byte) (int, error) {
 func (e *errorResponseWriter) WriteHeader(_ int) {
 	// Do nothing
 }
+
+func TestResponder(t *testing.T) {
+	handler := httpx.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) error {
+		return errors.New("boom")
+	})
+
+	// Test case: handler errors use the attached responder
+	t.Run("Custom", func(t *testing.T) {
+		wrapped := middleware.Responder(textResponder{})(handler)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		w := httptest.NewRecorder()
+
+		wrapped.ServeHTTP(w, req)
+
+		if w.Body.String() != "error: internal server error: boom" {
+			t.Errorf("Expected plain-text error body, got '%s'", w.Body.String())
+		}
+	})
+
+	// Test case: without the middleware the default JSON responder is used
+	t.Run("Default", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+
+		if w.Header().Get("Content-Type") != "application/json" {
+			t.Errorf("Expected JSON error, got Content-Type '%s'", w.Header().Get("Content-Type"))
+		}
+	})
+}
+
+func TestTransform(t *testing.T) {
+	// Test case: a raw object is wrapped in the envelope
+	t.Run("Success", func(t *testing.T) {
+		handler := httpx.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) error {
+			return httpx.JSON(w, map[string]string{"id": "1"}, http.StatusOK)
+		})
+		wrapped := middleware.Transform(httpx.Envelope)(handler)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		w := httptest.NewRecorder()
+
+		wrapped.ServeHTTP(w, req)
+
+		expected := `{"data":{"id":"1"},"success":true}`
+		if strings.TrimSpace(w.Body.String()) != expected {
+			t.Errorf("Expected body %s, got %s", expected, w.Body.String())
+		}
+	})
+
+	// Test case: errors are wrapped with success false
+	t.Run("Error", func(t *testing.T) {
+		handler := httpx.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) error {
+			return httpx.NewStatusError(http.StatusNotFound, errors.New("no such user"))
+		})
+		wrapped := middleware.Transform(httpx.Envelope)(handler)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		w := httptest.NewRecorder()
+
+		wrapped.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status code %d, got %d", http.StatusNotFound, w.Code)
+		}
+		expected := `{"error":"no such user","success":false}`
+		if strings.TrimSpace(w.Body.String()) != expected {
+			t.Errorf("Expected body %s, got %s", expected, w.Body.String())
+		}
+	})
+
+	// Test case: without the middleware responses are unchanged
+	t.Run("NotApplied", func(t *testing.T) {
+		handler := httpx.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) error {
+			return httpx.JSON(w, map[string]string{"id": "1"}, http.StatusOK)
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+
+		if strings.TrimSpace(w.Body.String()) != `{"id":"1"}` {
+			t.Errorf("Expected unwrapped body, got %s", w.Body.String())
+		}
+	})
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// MethodOverrideHeader is the header read by MethodOverride.
+const MethodOverrideHeader = "X-HTTP-Method-Override"
+
+// MethodOverrideField is the form field read by MethodOverride.
+const MethodOverrideField = "_method"
+
+// overridableMethods lists the methods a POST request may be rewritten to.
+var overridableMethods = map[string]bool{
+	http.MethodPut:    true,
+	http.MethodPatch:  true,
+	http.MethodDelete: true,
+}
+
+// MethodOverride returns a middleware that lets POST requests, such as those
+// sent by HTML forms, stand in for PUT, PATCH and DELETE. The method is taken
+// from the X-HTTP-Method-Override header or, for URL-encoded forms, the
+// _method field. Other requests and other target methods are left untouched.
+//
+// Since routing happens before router middleware runs, MethodOverride must
+// wrap the router itself rather than be added with Use.
+//
+// Example:
+//
+//	http.ListenAndServe(":8080", middleware.MethodOverride()(router))
+func MethodOverride() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPost {
+				method := r.Header.Get(MethodOverrideHeader)
+				if method == "" && isURLEncodedForm(r) {
+					method = r.PostFormValue(MethodOverrideField)
+				}
+
+				method = strings.ToUpper(strings.TrimSpace(method))
+				if overridableMethods[method] {
+					r = r.Clone(r.Context())
+					r.Method = method
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// isURLEncodedForm reports whether the request body is a URL-encoded form.
+func isURLEncodedForm(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && mediaType == "application/x-www-form-urlencoded"
+}
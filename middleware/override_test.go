@@ -0,0 +1,56 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/vibe-go/vibe/middleware"
+)
+
+func TestMethodOverride(t *testing.T) {
+	mux := http.NewServeMux()
+	for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete} {
+		mux.HandleFunc(method+" /items/1", func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(method + " " + r.FormValue("name")))
+		})
+	}
+
+	handler := middleware.MethodOverride()(mux)
+
+	tests := []struct {
+		name        string
+		method      string
+		contentType string
+		header      string
+		body        string
+		expected    string
+	}{
+		{"FormField", http.MethodPost, "application/x-www-form-urlencoded", "", "_method=DELETE", "DELETE "},
+		{"FormFieldKeepsForm", http.MethodPost, "application/x-www-form-urlencoded", "", "_method=put&name=x", "PUT x"},
+		{"Header", http.MethodPost, "", "DELETE", "", "DELETE "},
+		{"UnsafeTargetIgnored", http.MethodPost, "application/x-www-form-urlencoded", "", "_method=GET", "POST "},
+		{"NonPostIgnored", http.MethodGet, "", "DELETE", "", "GET "},
+		{"JSONBodyIgnored", http.MethodPost, "application/json", "", `{"_method":"DELETE"}`, "POST "},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/items/1", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			if tt.header != "" {
+				req.Header.Set(middleware.MethodOverrideHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			if w.Body.String() != tt.expected {
+				t.Errorf("Expected body '%s', got '%s'", tt.expected, w.Body.String())
+			}
+		})
+	}
+}
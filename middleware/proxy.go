@@ -0,0 +1,149 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// ProxyTrust is a set of proxy addresses whose X-Forwarded-* headers are
+// honoured. It is shared by the middlewares that rely on those headers, such
+// as RealIP and RequireHTTPS, so that they agree on which peers to believe.
+type ProxyTrust struct {
+	prefixes []netip.Prefix
+}
+
+// TrustedProxies returns a ProxyTrust for the given CIDR ranges. Plain IP
+// addresses are accepted as single-address ranges. It panics if a range is
+// invalid, since proxy configuration is fixed at startup.
+//
+// Example:
+//
+//	proxies := middleware.TrustedProxies("10.0.0.0/8", "192.168.1.10")
+//	router.Use(middleware.RealIP(proxies))
+func TrustedProxies(cidrs ...string) *ProxyTrust {
+	trust := &ProxyTrust{}
+	for _, cidr := range cidrs {
+		prefix, err := parsePrefix(cidr)
+		if err != nil {
+			panic(fmt.Sprintf("middleware: invalid trusted proxy %q: %v", cidr, err))
+		}
+		trust.prefixes = append(trust.prefixes, prefix)
+	}
+	return trust
+}
+
+// parsePrefix parses a CIDR range or a single IP address.
+func parsePrefix(s string) (netip.Prefix, error) {
+	s = strings.TrimSpace(s)
+	if strings.Contains(s, "/") {
+		prefix, err := netip.ParsePrefix(s)
+		return prefix.Masked(), err
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// Trusts reports whether addr, an IP address with or without a port such as
+// http.Request.RemoteAddr, belongs to a trusted proxy. A nil ProxyTrust
+// trusts nobody.
+func (p *ProxyTrust) Trusts(addr string) bool {
+	if p == nil {
+		return false
+	}
+	ip, ok := parseIP(addr)
+	if !ok {
+		return false
+	}
+	for _, prefix := range p.prefixes {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseIP parses an IP address, stripping a port if present.
+func parseIP(addr string) (netip.Addr, bool) {
+	addr = strings.TrimSpace(addr)
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	ip, err := netip.ParseAddr(addr)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return ip.Unmap(), true
+}
+
+// RealIP returns a middleware that sets r.RemoteAddr to the client's
+// address as reported by trusted proxies. X-Forwarded-For is walked from the
+// nearest hop outwards, skipping trusted proxies, and the first untrusted
+// address is taken as the client; X-Real-IP is used if X-Forwarded-For is
+// absent. Requests whose peer is not a trusted proxy are left untouched, so
+// clients cannot spoof their address. The original peer address remains
+// available through PeerAddr.
+func RealIP(trust *ProxyTrust) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if ip := clientIP(trust, r); ip != "" {
+				ctx := r.Context()
+				if _, ok := ctx.Value(peerAddrKey{}).(string); !ok {
+					ctx = context.WithValue(ctx, peerAddrKey{}, r.RemoteAddr)
+				}
+				r = r.Clone(ctx)
+				r.RemoteAddr = ip
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// peerAddrKey is the context key for the peer address RealIP replaced.
+type peerAddrKey struct{}
+
+// PeerAddr returns the address of the peer the request was received from,
+// i.e. r.RemoteAddr as it was before RealIP replaced it with the client's
+// address. Checks against a ProxyTrust should use it rather than
+// r.RemoteAddr.
+func PeerAddr(r *http.Request) string {
+	if addr, ok := r.Context().Value(peerAddrKey{}).(string); ok {
+		return addr
+	}
+	return r.RemoteAddr
+}
+
+// clientIP returns the client address forwarded by trusted proxies, or an
+// empty string if the request did not come through one.
+func clientIP(trust *ProxyTrust, r *http.Request) string {
+	if !trust.Trusts(PeerAddr(r)) {
+		return ""
+	}
+
+	if forwarded := r.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+		hops := strings.Split(strings.Join(forwarded, ","), ",")
+		client := ""
+		for i := len(hops) - 1; i >= 0; i-- {
+			ip, ok := parseIP(hops[i])
+			if !ok {
+				break
+			}
+			client = ip.String()
+			if !trust.Trusts(client) {
+				break
+			}
+		}
+		return client
+	}
+
+	if ip, ok := parseIP(r.Header.Get("X-Real-IP")); ok {
+		return ip.String()
+	}
+	return ""
+}
@@ -0,0 +1,177 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/vibe-go/vibe/middleware"
+)
+
+func TestTrustedProxies(t *testing.T) {
+	trust := middleware.TrustedProxies("10.0.0.0/8", "192.168.1.10", "::1")
+
+	tests := []struct {
+		addr     string
+		expected bool
+	}{
+		{"10.1.2.3:4567", true},
+		{"10.1.2.3", true},
+		{"192.168.1.10:80", true},
+		{"192.168.1.11:80", false},
+		{"[::1]:8080", true},
+		{"203.0.113.7:1234", false},
+		{"not-an-ip", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			if got := trust.Trusts(tt.addr); got != tt.expected {
+				t.Errorf("Expected Trusts(%s) %v, got %v", tt.addr, tt.expected, got)
+			}
+		})
+	}
+
+	t.Run("InvalidPanics", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("Expected TrustedProxies to panic on an invalid range")
+			}
+		}()
+		middleware.TrustedProxies("10.0.0.0/99")
+	})
+}
+
+func TestRealIP(t *testing.T) {
+	var got string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.RemoteAddr
+		w.WriteHeader(http.StatusOK)
+	})
+
+	wrapped := middleware.RealIP(middleware.TrustedProxies("10.0.0.0/8"))(handler)
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  string
+		realIP     string
+		expected   string
+	}{
+		{"TrustedForwardedFor", "10.0.0.1:1234", "203.0.113.7", "", "203.0.113.7"},
+		{"SkipsTrustedHops", "10.0.0.1:1234", "198.51.100.2, 203.0.113.7, 10.0.0.5", "", "203.0.113.7"},
+		{"TrustedRealIP", "10.0.0.1:1234", "", "203.0.113.7", "203.0.113.7"},
+		{"UntrustedIgnored", "198.51.100.9:1234", "203.0.113.7", "203.0.113.8", "198.51.100.9:1234"},
+		{"NoHeaders", "10.0.0.1:1234", "", "", "10.0.0.1:1234"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.forwarded != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			if tt.realIP != "" {
+				req.Header.Set("X-Real-IP", tt.realIP)
+			}
+
+			wrapped.ServeHTTP(httptest.NewRecorder(), req)
+
+			if got != tt.expected {
+				t.Errorf("Expected RemoteAddr '%s', got '%s'", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRequireHTTPSTrustedProxies(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	wrapped := middleware.RequireHTTPS(
+		middleware.WithTrustedProxies(middleware.TrustedProxies("10.0.0.0/8")))(handler)
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		expected   int
+	}{
+		{"TrustedProxy", "10.0.0.1:1234", http.StatusOK},
+		{"UntrustedSource", "203.0.113.7:1234", http.StatusPermanentRedirect},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("X-Forwarded-Proto", "https")
+			w := httptest.NewRecorder()
+
+			wrapped.ServeHTTP(w, req)
+
+			if w.Code != tt.expected {
+				t.Errorf("Expected status code %d, got %d", tt.expected, w.Code)
+			}
+		})
+	}
+}
+
+func TestRequireHTTPSBehindRealIP(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	proxies := middleware.TrustedProxies("10.0.0.0/8")
+
+	tests := []struct {
+		name       string
+		wrapped    http.Handler
+		remoteAddr string
+		expected   int
+	}{
+		{"TrustedProxy", middleware.RealIP(proxies)(middleware.RequireHTTPS(middleware.WithTrustedProxies(proxies))(handler)), "10.0.0.5:1234", http.StatusOK},
+		{"UntrustedSource", middleware.RealIP(proxies)(middleware.RequireHTTPS(middleware.WithTrustedProxies(proxies))(handler)), "198.51.100.9:1234", http.StatusPermanentRedirect},
+		{"NoTrustedProxies", middleware.RequireHTTPS()(handler), "10.0.0.5:1234", http.StatusPermanentRedirect},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "http://example.com/x", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("X-Forwarded-For", "203.0.113.9")
+			req.Header.Set("X-Forwarded-Proto", "https")
+			w := httptest.NewRecorder()
+
+			tt.wrapped.ServeHTTP(w, req)
+
+			if w.Code != tt.expected {
+				t.Errorf("Expected status code %d, got %d", tt.expected, w.Code)
+			}
+		})
+	}
+}
+
+func TestPeerAddr(t *testing.T) {
+	var peer, remote string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		peer, remote = middleware.PeerAddr(r), r.RemoteAddr
+	})
+
+	proxies := middleware.TrustedProxies("10.0.0.0/8")
+	wrapped := middleware.RealIP(proxies)(middleware.RealIP(proxies)(handler))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.0.0.5:1234"
+	req.Header.Set("X-Forwarded-For", "203.0.113.9")
+
+	wrapped.ServeHTTP(httptest.NewRecorder(), req)
+
+	if peer != "10.0.0.5:1234" {
+		t.Errorf("Expected peer address '10.0.0.5:1234', got '%s'", peer)
+	}
+	if remote != "203.0.113.9" {
+		t.Errorf("Expected RemoteAddr '203.0.113.9', got '%s'", remote)
+	}
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// RequestIDHeader is the header used to read and return request IDs.
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDKey is the context key for the request ID.
+type requestIDKey struct{}
+
+// RequestID returns a middleware that assigns each request an ID, taken from
+// the incoming X-Request-ID header or generated if absent. The ID is stored
+// in the request context and echoed in the X-Request-ID response header.
+func RequestID() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(RequestIDHeader)
+			if id == "" {
+				id = newRequestID()
+			}
+
+			w.Header().Set(RequestIDHeader, id)
+			next.ServeHTTP(w, r.WithContext(ContextWithRequestID(r.Context(), id)))
+		})
+	}
+}
+
+// ContextWithRequestID returns a copy of ctx carrying the request ID id, as
+// stored by RequestID.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFrom returns the request ID stored in ctx by RequestID, or an
+// empty string if there is none.
+func RequestIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// newRequestID returns a random 128-bit hex-encoded ID.
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,123 @@
+package middleware_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/vibe-go/vibe/httpx"
+	"github.com/vibe-go/vibe/middleware"
+)
+
+func TestRequestID(t *testing.T) {
+	var got string
+	handler := httpx.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		got = middleware.RequestIDFrom(r.Context())
+		w.WriteHeader(http.StatusOK)
+		return nil
+	})
+
+	wrapped := middleware.RequestID()(handler)
+
+	// Test case: ID is generated when absent
+	t.Run("Generated", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		w := httptest.NewRecorder()
+
+		wrapped.ServeHTTP(w, req)
+
+		if got == "" {
+			t.Error("Expected a generated request ID in context")
+		}
+		if w.Header().Get(middleware.RequestIDHeader) != got {
+			t.Errorf("Expected response header '%s', got '%s'", got, w.Header().Get(middleware.RequestIDHeader))
+		}
+	})
+
+	// Test case: incoming ID is preserved
+	t.Run("Incoming", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(middleware.RequestIDHeader, "abc-123")
+		w := httptest.NewRecorder()
+
+		wrapped.ServeHTTP(w, req)
+
+		if got != "abc-123" {
+			t.Errorf("Expected request ID 'abc-123', got '%s'", got)
+		}
+	})
+}
+
+func TestRecoveryWithRequestID(t *testing.T) {
+	handler := httpx.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) error {
+		panic("boom")
+	})
+
+	var buf bytes.Buffer
+	wrapped := middleware.RequestID()(middleware.Recovery(log.New(&buf, "", 0))(handler))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(middleware.RequestIDHeader, "req-42")
+	w := httptest.NewRecorder()
+
+	wrapped.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status code %d, got %d", http.StatusInternalServerError, w.Code)
+	}
+
+	var body map[string]string
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+
+	if body["request_id"] != "req-42" {
+		t.Errorf("Expected request_id 'req-42', got '%s'", body["request_id"])
+	}
+	expected := "internal server error: boom"
+	if body["error"] != expected {
+		t.Errorf("Expected error '%s', got '%s'", expected, body["error"])
+	}
+	if !strings.Contains(body["error"], "boom") {
+		t.Errorf("Expected error to contain panic message, got '%s'", body["error"])
+	}
+
+	if !strings.Contains(buf.String(), "req-42") {
+		t.Errorf("Expected log to contain request ID, got: %s", buf.String())
+	}
+}
+
+// requestIDResponder records the request ID carried by the error.
+type requestIDResponder struct {
+	id *string
+}
+
+func (rr requestIDResponder) Error(w http.ResponseWriter, _ *http.Request, err error, status int) error {
+	if withID, ok := err.(interface{ RequestID() string }); ok {
+		*rr.id = withID.RequestID()
+	}
+	w.WriteHeader(status)
+	return nil
+}
+
+func TestRecoveryRequestIDCustomResponder(t *testing.T) {
+	handler := httpx.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) error {
+		panic("boom")
+	})
+
+	var id string
+	wrapped := middleware.RequestID()(middleware.Recovery(log.New(&bytes.Buffer{}, "", 0),
+		middleware.WithRecoveryResponder(requestIDResponder{&id}))(handler))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(middleware.RequestIDHeader, "req-7")
+	wrapped.ServeHTTP(httptest.NewRecorder(), req)
+
+	if id != "req-7" {
+		t.Errorf("Expected the responder to see request ID 'req-7', got '%s'", id)
+	}
+}
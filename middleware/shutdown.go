@@ -0,0 +1,139 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/vibe-go/vibe/httpx"
+)
+
+// ShutdownOption configures the DuringShutdown middleware.
+type ShutdownOption func(*shutdownConfig)
+
+// shutdownConfig holds the configuration for the DuringShutdown middleware.
+type shutdownConfig struct {
+	retryAfter time.Duration
+}
+
+// WithRetryAfter sets the delay advertised in the Retry-After header of
+// requests rejected during shutdown. The default is 5 seconds.
+func WithRetryAfter(d time.Duration) ShutdownOption {
+	return func(c *shutdownConfig) {
+		c.retryAfter = d
+	}
+}
+
+// DuringShutdown returns a middleware that rejects new requests with 503
+// Service Unavailable and a Retry-After header once signal is closed, so
+// that clients retry against another instance instead of having their
+// connection dropped. Requests already being handled run to completion.
+//
+// Close signal before calling http.Server.Shutdown, and keep serving for a
+// drain delay so that load balancers see the 503s and stop routing to the
+// instance. Shutdown closes the listeners first, so closing signal from a
+// RegisterOnShutdown hook is too late for any request to observe it.
+//
+// Example:
+//
+//	shutdown := make(chan struct{})
+//	router.Use(middleware.DuringShutdown(shutdown))
+//	...
+//	<-stop // e.g. SIGTERM
+//	close(shutdown)
+//	time.Sleep(5 * time.Second) // drain delay
+//	server.Shutdown(ctx)
+func DuringShutdown(signal <-chan struct{}, options ...ShutdownOption) func(next http.Handler) http.Handler {
+	cfg := &shutdownConfig{retryAfter: 5 * time.Second}
+	for _, option := range options {
+		option(cfg)
+	}
+	retryAfter := strconv.Itoa(int(cfg.retryAfter.Round(time.Second) / time.Second))
+
+	return func(next http.Handler) http.Handler {
+		return httpx.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			select {
+			case <-signal:
+				w.Header().Set("Retry-After", retryAfter)
+				w.Header().Set("Connection", "close")
+				return httpx.Error(w, errors.New("server is shutting down"), http.StatusServiceUnavailable)
+			default:
+			}
+
+			next.ServeHTTP(w, r)
+			return nil
+		})
+	}
+}
+
+// InFlight counts the requests currently being handled. Its Middleware must
+// wrap the handlers to be counted; Shutdown uses the count to report how
+// much work was still running when shutdown began.
+type InFlight struct {
+	n atomic.Int64
+}
+
+// NewInFlight returns a request counter starting at zero.
+func NewInFlight() *InFlight {
+	return &InFlight{}
+}
+
+// Middleware returns a middleware that counts requests while they are
+// being handled.
+func (c *InFlight) Middleware() func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			c.n.Add(1)
+			defer c.n.Add(-1)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Count returns the number of requests currently being handled.
+func (c *InFlight) Count() int64 {
+	return c.n.Load()
+}
+
+// DrainReport describes a graceful shutdown performed by Shutdown.
+type DrainReport struct {
+	// InFlight is the number of requests being handled when shutdown began.
+	InFlight int64
+	// Duration is how long the server took to drain.
+	Duration time.Duration
+	// Err is the error returned by http.Server.Shutdown, e.g. when ctx
+	// expired before all requests completed.
+	Err error
+}
+
+// Shutdown gracefully shuts down server with http.Server.Shutdown and, once
+// it returns, calls report with the number of requests in flight when it
+// started and how long draining took. These figures help tune shutdown
+// timeouts. counter may be nil, in which case InFlight is reported as 0;
+// report may be nil. It returns the error from http.Server.Shutdown.
+//
+// Example:
+//
+//	inflight := middleware.NewInFlight()
+//	router.Use(inflight.Middleware())
+//	...
+//	middleware.Shutdown(ctx, server, inflight, func(d middleware.DrainReport) {
+//		log.Printf("drained %d requests in %v", d.InFlight, d.Duration)
+//	})
+func Shutdown(ctx context.Context, server *http.Server, counter *InFlight, report func(DrainReport)) error {
+	var inFlight int64
+	if counter != nil {
+		inFlight = counter.Count()
+	}
+
+	start := time.Now()
+	err := server.Shutdown(ctx)
+
+	if report != nil {
+		report(DrainReport{InFlight: inFlight, Duration: time.Since(start), Err: err})
+	}
+	return err
+}
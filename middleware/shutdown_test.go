@@ -0,0 +1,165 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/vibe-go/vibe/httpx"
+	"github.com/vibe-go/vibe/middleware"
+)
+
+func TestDuringShutdown(t *testing.T) {
+	signal := make(chan struct{})
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	handler := httpx.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		if r.URL.Path == "/slow" {
+			close(started)
+			<-release
+		}
+		w.WriteHeader(http.StatusOK)
+		return nil
+	})
+
+	wrapped := middleware.DuringShutdown(signal, middleware.WithRetryAfter(30*time.Second))(handler)
+
+	// Test case: requests are served before the signal
+	t.Run("BeforeSignal", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		w := httptest.NewRecorder()
+
+		wrapped.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+		}
+	})
+
+	// Test case: in-flight requests complete, new ones are rejected
+	t.Run("AfterSignal", func(t *testing.T) {
+		inFlight := httptest.NewRecorder()
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			wrapped.ServeHTTP(inFlight, httptest.NewRequest(http.MethodGet, "/slow", nil))
+		}()
+
+		<-started
+		close(signal)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		w := httptest.NewRecorder()
+
+		wrapped.ServeHTTP(w, req)
+
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("Expected status code %d, got %d", http.StatusServiceUnavailable, w.Code)
+		}
+		if w.Header().Get("Retry-After") != "30" {
+			t.Errorf("Expected Retry-After '30', got '%s'", w.Header().Get("Retry-After"))
+		}
+
+		close(release)
+		<-done
+
+		if inFlight.Code != http.StatusOK {
+			t.Errorf("Expected in-flight status code %d, got %d", http.StatusOK, inFlight.Code)
+		}
+	})
+}
+
+func TestDuringShutdownDrainDelay(t *testing.T) {
+	signal := make(chan struct{})
+	handler := httpx.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) error {
+		w.WriteHeader(http.StatusOK)
+		return nil
+	})
+
+	server := httptest.NewServer(middleware.DuringShutdown(signal)(handler))
+	defer server.Close()
+
+	// Close the signal first; the server keeps accepting requests during the
+	// drain delay and rejects them until Shutdown is called.
+	close(signal)
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected request during drain delay to be answered, got %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected status code %d, got %d", http.StatusServiceUnavailable, resp.StatusCode)
+	}
+	if resp.Header.Get("Retry-After") != "5" {
+		t.Errorf("Expected Retry-After '5', got '%s'", resp.Header.Get("Retry-After"))
+	}
+
+	if err := server.Config.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown() returned error: %v", err)
+	}
+}
+
+func TestShutdownReport(t *testing.T) {
+	inflight := middleware.NewInFlight()
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusOK)
+	})
+
+	server := httptest.NewUnstartedServer(inflight.Middleware()(handler))
+	server.Start()
+	defer server.Close()
+
+	// Simulate a request that is still running when shutdown begins
+	respErr := make(chan error, 1)
+	go func() {
+		resp, err := http.Get(server.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		respErr <- err
+	}()
+	<-started
+
+	if inflight.Count() != 1 {
+		t.Fatalf("Expected 1 request in flight, got %d", inflight.Count())
+	}
+
+	reports := make(chan middleware.DrainReport, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- middleware.Shutdown(context.Background(), server.Config, inflight, func(d middleware.DrainReport) {
+			reports <- d
+		})
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+
+	if err := <-done; err != nil {
+		t.Errorf("Shutdown() returned error: %v", err)
+	}
+	if err := <-respErr; err != nil {
+		t.Errorf("Expected in-flight request to complete, got %v", err)
+	}
+
+	report := <-reports
+	if report.InFlight != 1 {
+		t.Errorf("Expected 1 request in flight at shutdown, got %d", report.InFlight)
+	}
+	if report.Duration < 20*time.Millisecond {
+		t.Errorf("Expected drain to take at least 20ms, got %v", report.Duration)
+	}
+	if inflight.Count() != 0 {
+		t.Errorf("Expected no requests in flight after drain, got %d", inflight.Count())
+	}
+}
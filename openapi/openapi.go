@@ -0,0 +1,154 @@
+// Package openapi generates OpenAPI 3 documents from the routes registered
+// on a Vibe router.
+//
+// The generated document lists every path and method along with the path
+// parameters found in the route patterns. It does not describe request or
+// response schemas, but it can be edited before being served to add them.
+package openapi
+
+import (
+	"strings"
+
+	"github.com/vibe-go/vibe"
+)
+
+// Version is the OpenAPI specification version of generated documents.
+const Version = "3.0.3"
+
+// Document is an OpenAPI document.
+type Document struct {
+	OpenAPI string              `json:"openapi"`
+	Info    Info                `json:"info"`
+	Paths   map[string]PathItem `json:"paths"`
+}
+
+// Info holds the API metadata of a document.
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// PathItem maps lower-case HTTP methods to the operations of a path.
+type PathItem map[string]*Operation
+
+// Operation describes a single API operation on a path.
+type Operation struct {
+	OperationID string              `json:"operationId,omitempty"`
+	Summary     string              `json:"summary,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+}
+
+// Parameter describes a single operation parameter.
+type Parameter struct {
+	Name     string `json:"name"`
+	In       string `json:"in"`
+	Required bool   `json:"required"`
+	Schema   Schema `json:"schema"`
+}
+
+// Schema is a minimal JSON schema.
+type Schema struct {
+	Type string `json:"type"`
+}
+
+// Response describes a single response of an operation.
+type Response struct {
+	Description string `json:"description"`
+}
+
+// Option defines a function that configures the generated document.
+type Option func(*Document)
+
+// WithTitle sets the API title. The default is "API".
+func WithTitle(title string) Option {
+	return func(d *Document) {
+		d.Info.Title = title
+	}
+}
+
+// WithVersion sets the API version. The default is "1.0.0".
+func WithVersion(version string) Option {
+	return func(d *Document) {
+		d.Info.Version = version
+	}
+}
+
+// WithDescription sets the API description.
+func WithDescription(description string) Option {
+	return func(d *Document) {
+		d.Info.Description = description
+	}
+}
+
+// Generate builds an OpenAPI document describing the routes registered on router.
+//
+// Example:
+//
+//	doc := openapi.Generate(router, openapi.WithTitle("Todo API"))
+//	json.NewEncoder(os.Stdout).Encode(doc)
+func Generate(router *vibe.Router, options ...Option) *Document {
+	doc := &Document{
+		OpenAPI: Version,
+		Info: Info{
+			Title:   "API",
+			Version: "1.0.0",
+		},
+		Paths: make(map[string]PathItem),
+	}
+
+	for _, option := range options {
+		option(doc)
+	}
+
+	for _, route := range router.Routes() {
+		path, params := convertPattern(route.Pattern)
+
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = make(PathItem)
+			doc.Paths[path] = item
+		}
+
+		op := &Operation{
+			Responses: map[string]Response{
+				"default": {Description: "Default response"},
+			},
+		}
+		for _, name := range params {
+			op.Parameters = append(op.Parameters, Parameter{
+				Name:     name,
+				In:       "path",
+				Required: true,
+				Schema:   Schema{Type: "string"},
+			})
+		}
+
+		item[strings.ToLower(route.Method)] = op
+	}
+
+	return doc
+}
+
+// convertPattern converts a ServeMux pattern to an OpenAPI path template and
+// returns the names of its path parameters. Wildcards such as "{path...}"
+// become "{path}" and the "{$}" end anchor is dropped.
+func convertPattern(pattern string) (string, []string) {
+	segments := strings.Split(pattern, "/")
+	var params []string
+
+	for i, segment := range segments {
+		if segment == "{$}" {
+			segments[i] = ""
+			continue
+		}
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			name := strings.TrimSuffix(strings.Trim(segment, "{}"), "...")
+			segments[i] = "{" + name + "}"
+			params = append(params, name)
+		}
+	}
+
+	return strings.Join(segments, "/"), params
+}
@@ -0,0 +1,156 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/vibe-go/vibe"
+	"github.com/vibe-go/vibe/httpx"
+	"github.com/vibe-go/vibe/openapi"
+)
+
+func newRouter() *vibe.Router {
+	router := vibe.New()
+
+	handler := func(w http.ResponseWriter, _ *http.Request) error {
+		return httpx.JSON(w, map[string]string{"status": "ok"}, http.StatusOK)
+	}
+
+	router.Get("/users", handler)
+	router.Post("/users", handler)
+	router.Get("/users/{id}", handler)
+	router.Get("/files/{path...}", handler)
+
+	return router
+}
+
+func TestGenerate(t *testing.T) {
+	doc := openapi.Generate(newRouter(), openapi.WithTitle("Test API"))
+
+	if doc.OpenAPI != openapi.Version {
+		t.Errorf("Expected OpenAPI version %s, got %s", openapi.Version, doc.OpenAPI)
+	}
+
+	if doc.Info.Title != "Test API" {
+		t.Errorf("Expected title 'Test API', got '%s'", doc.Info.Title)
+	}
+
+	users, ok := doc.Paths["/users"]
+	if !ok {
+		t.Fatal("Expected /users path in document")
+	}
+	if users["get"] == nil || users["post"] == nil {
+		t.Errorf("Expected get and post operations on /users, got %v", users)
+	}
+
+	user, ok := doc.Paths["/users/{id}"]
+	if !ok {
+		t.Fatal("Expected /users/{id} path in document")
+	}
+	params := user["get"].Parameters
+	if len(params) != 1 || params[0].Name != "id" || params[0].In != "path" || !params[0].Required {
+		t.Errorf("Expected required path parameter 'id', got %+v", params)
+	}
+
+	if _, ok := doc.Paths["/files/{path}"]; !ok {
+		t.Errorf("Expected wildcard pattern to become /files/{path}, got %v", doc.Paths)
+	}
+}
+
+func TestServeUI(t *testing.T) {
+	router := newRouter()
+	openapi.ServeUI(router, "/docs/openapi.json")
+
+	t.Run("Spec", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/docs/openapi.json", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+		}
+
+		var doc openapi.Document
+		if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+			t.Fatalf("Expected valid JSON spec, got error: %v", err)
+		}
+
+		if _, ok := doc.Paths["/users"]; !ok {
+			t.Errorf("Expected spec to contain /users, got %v", doc.Paths)
+		}
+		if _, ok := doc.Paths["/docs/assets/"]; ok {
+			t.Errorf("Expected spec not to contain the UI assets route")
+		}
+		if _, ok := doc.Paths["/docs/openapi.json"]; ok {
+			t.Errorf("Expected spec not to contain the documentation routes")
+		}
+	})
+
+	t.Run("UI", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/docs/", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status code %d, got %d", http.StatusOK, w.Code)
+		}
+
+		if !strings.Contains(w.Body.String(), "/docs/openapi.json") {
+			t.Errorf("Expected UI page to reference the spec, got %s", w.Body.String())
+		}
+		if strings.Contains(w.Body.String(), "https://") {
+			t.Errorf("Expected UI page to load only local assets, got %s", w.Body.String())
+		}
+	})
+
+	t.Run("Assets", func(t *testing.T) {
+		page := httptest.NewRecorder()
+		router.ServeHTTP(page, httptest.NewRequest(http.MethodGet, "/docs/", nil))
+
+		// Every asset the page loads must be served, not just the page.
+		assets := map[string]bool{"assets/swagger-ui.css": true, "assets/swagger-ui-bundle.js": true}
+		for _, m := range regexp.MustCompile(`(?:src|href)="(assets/[^"]+)"`).FindAllStringSubmatch(page.Body.String(), -1) {
+			assets[m[1]] = true
+		}
+
+		for asset := range assets {
+			if !strings.Contains(page.Body.String(), `"`+asset+`"`) {
+				t.Errorf("Expected the page to load %s", asset)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/docs/"+asset, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Errorf("Expected status code %d for %s, got %d", http.StatusOK, asset, w.Code)
+			}
+			if w.Body.Len() == 0 {
+				t.Errorf("Expected a non-empty body for %s", asset)
+			}
+		}
+	})
+
+	t.Run("NoLeadingSlash", func(t *testing.T) {
+		router := newRouter()
+		openapi.ServeUI(router, "api/openapi.json")
+
+		for _, path := range []string{"/api/openapi.json", "/api/"} {
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Errorf("Expected status code %d for %s, got %d", http.StatusOK, path, w.Code)
+			}
+		}
+	})
+}
@@ -0,0 +1,89 @@
+package openapi
+
+import (
+	"bytes"
+	"embed"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"path"
+
+	"github.com/vibe-go/vibe"
+	"github.com/vibe-go/vibe/httpx"
+)
+
+// The Swagger UI distribution is vendored into ui/assets, so that the page
+// works offline and under a strict Content-Security-Policy.
+//go:generate sh -c "curl -fsSL https://registry.npmjs.org/swagger-ui-dist/-/swagger-ui-dist-5.17.14.tgz | tar -xz -C ui/assets --strip-components=1 package/swagger-ui.css package/swagger-ui-bundle.js package/LICENSE"
+
+//go:embed ui
+var uiFS embed.FS
+
+// uiTemplate renders the Swagger UI page, which loads its scripts and
+// styles from uiAssets.
+var uiTemplate = template.Must(template.ParseFS(uiFS, "ui/index.html"))
+
+// uiAssets holds the static files of the Swagger UI page.
+var uiAssets = func() http.FileSystem {
+	assets, err := fs.Sub(uiFS, "ui/assets")
+	if err != nil {
+		panic(err)
+	}
+	return http.FS(assets)
+}()
+
+// ServeUI registers GET routes on router for specPath, serving the OpenAPI
+// document generated from the router as JSON, and for the directory
+// containing specPath, serving a Swagger UI page for that document along
+// with its embedded assets. The document is generated on each request, so
+// routes registered later are included; the documentation routes themselves
+// are left out.
+//
+// Example:
+//
+//	openapi.ServeUI(router, "/docs/openapi.json", openapi.WithTitle("Todo API"))
+//	// Swagger UI: /docs/
+func ServeUI(router *vibe.Router, specPath string, options ...Option) {
+	// path.Join cleans the path and adds the leading slash patterns need.
+	specPath = path.Join("/", specPath)
+	uiPath := path.Dir(specPath)
+	if uiPath != "/" {
+		uiPath += "/"
+	}
+	assetsPath := uiPath + "assets/"
+
+	router.Get(specPath, func(w http.ResponseWriter, _ *http.Request) error {
+		doc := Generate(router, options...)
+		delete(doc.Paths, specPath)
+		delete(doc.Paths, uiPath)
+		delete(doc.Paths, assetsPath)
+		return httpx.JSON(w, doc, http.StatusOK)
+	})
+
+	assets := http.StripPrefix(assetsPath, http.FileServer(uiAssets))
+	router.Get(assetsPath, func(w http.ResponseWriter, r *http.Request) error {
+		assets.ServeHTTP(w, r)
+		return nil
+	})
+
+	router.Get(uiPath+"{$}", func(w http.ResponseWriter, _ *http.Request) error {
+		doc := &Document{Info: Info{Title: "API"}}
+		for _, option := range options {
+			option(doc)
+		}
+
+		var buf bytes.Buffer
+		err := uiTemplate.Execute(&buf, map[string]string{
+			"Title":    doc.Info.Title,
+			"SpecPath": specPath,
+		})
+		if err != nil {
+			return err
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		_, err = w.Write(buf.Bytes())
+		return err
+	})
+}
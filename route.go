@@ -0,0 +1,111 @@
+package vibe
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/vibe-go/vibe/httpx"
+	"github.com/vibe-go/vibe/middleware"
+)
+
+// routeNameKey is the context key for the name of the matched route.
+type routeNameKey struct{}
+
+// RouteName returns the name given to the matched route with Name, or
+// "" if the route is unnamed.
+func RouteName(r *http.Request) string {
+	name, _ := r.Context().Value(routeNameKey{}).(string)
+	return name
+}
+
+// RouteOption configures a single route registered with Handle or one of
+// the method helpers such as Get. The helpers accept route options and
+// middleware, either MiddlewareFunc or plain func(http.Handler) http.Handler
+// values, in the same variadic argument; they apply in the order given.
+// Registering a route with a value of any other type panics.
+//
+// Example:
+//
+//	router.Get("/users/{id}", getUser, vibe.Name("get_user"), requireAuth)
+type RouteOption interface {
+	applyRoute(*routeConfig)
+}
+
+// routeConfig holds the configuration of a route being registered.
+type routeConfig struct {
+	name string
+	mws  []MiddlewareFunc
+}
+
+// routeOptionFunc adapts a function to the RouteOption interface.
+type routeOptionFunc func(*routeConfig)
+
+func (f routeOptionFunc) applyRoute(c *routeConfig) {
+	f(c)
+}
+
+// applyRoute adds the middleware to the route.
+func (m MiddlewareFunc) applyRoute(c *routeConfig) {
+	c.mws = append(c.mws, m)
+}
+
+// newRouteConfig applies opts, route options and middleware, to a fresh
+// route configuration.
+func newRouteConfig(opts []any) routeConfig {
+	var c routeConfig
+	for _, opt := range opts {
+		switch o := opt.(type) {
+		case RouteOption:
+			o.applyRoute(&c)
+		case func(http.Handler) http.Handler:
+			c.mws = append(c.mws, o)
+		default:
+			panic(fmt.Sprintf("vibe: invalid route option of type %T", opt))
+		}
+	}
+	return c
+}
+
+// withMiddleware returns opts preceded by mws, for registering a route
+// through a Group or Scope.
+func withMiddleware(mws []MiddlewareFunc, opts []any) []any {
+	all := make([]any, 0, len(mws)+len(opts))
+	for _, mw := range mws {
+		all = append(all, mw)
+	}
+	return append(all, opts...)
+}
+
+// Name labels the route with a stable name, independent of its pattern,
+// for use in metrics and logs. The name is reported in Routes and set before
+// any middleware runs, so global middleware such as request logging can read
+// it with RouteName as well as the handler.
+//
+// Example:
+//
+//	router.Get("/users/{id}", getUser, vibe.Name("get_user"), requireAuth)
+func Name(name string) RouteOption {
+	return routeOptionFunc(func(c *routeConfig) {
+		c.name = name
+	})
+}
+
+// WithName is an alias for Name, matching the With prefix of the other
+// route options.
+func WithName(name string) RouteOption {
+	return Name(name)
+}
+
+// WithRouteTimeout limits the route to the given timeout. The router's own
+// timeout still applies, so this can only shorten it; use
+// WithoutTimeout on the router to give routes longer timeouts.
+func WithRouteTimeout(timeout time.Duration, options ...middleware.TimeoutOption) RouteOption {
+	return MiddlewareFunc(middleware.WithTimeout(timeout, options...))
+}
+
+// WithResponder writes the route's errors with responder instead of the
+// default responder.
+func WithResponder(responder httpx.ErrorResponder) RouteOption {
+	return MiddlewareFunc(middleware.Responder(responder))
+}
@@ -0,0 +1,335 @@
+// Package session provides server-side sessions for the Vibe framework.
+//
+// The session data lives in a Store and the client only holds a random
+// session ID in a cookie. An in-memory store is included; other backends
+// such as Redis or a database can be plugged in by implementing Store.
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/vibe-go/vibe/ctxval"
+	"github.com/vibe-go/vibe/httpx"
+)
+
+// DefaultCookieName is the default name of the cookie holding the session ID.
+const DefaultCookieName = "_session"
+
+// ErrNotFound is returned by a Store when no session exists for an ID.
+var ErrNotFound = errors.New("session: not found")
+
+// Store persists session data by session ID.
+type Store interface {
+	// Load returns the values of the session with the given ID, or
+	// ErrNotFound if there is none.
+	Load(ctx context.Context, id string) (map[string]interface{}, error)
+	// Save stores the values of the session with the given ID.
+	Save(ctx context.Context, id string, values map[string]interface{}) error
+	// Delete removes the session with the given ID.
+	Delete(ctx context.Context, id string) error
+}
+
+// MemoryStore is a Store that keeps sessions in process memory.
+// It is suitable for development and single-instance deployments.
+type MemoryStore struct {
+	mu       sync.RWMutex
+	sessions map[string]map[string]interface{}
+}
+
+// NewMemoryStore creates an empty in-memory session store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{sessions: make(map[string]map[string]interface{})}
+}
+
+// Load implements Store.
+func (s *MemoryStore) Load(_ context.Context, id string) (map[string]interface{}, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	values, ok := s.sessions[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return copyValues(values), nil
+}
+
+// Save implements Store.
+func (s *MemoryStore) Save(_ context.Context, id string, values map[string]interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sessions[id] = copyValues(values)
+	return nil
+}
+
+// Delete implements Store.
+func (s *MemoryStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.sessions, id)
+	return nil
+}
+
+func copyValues(values map[string]interface{}) map[string]interface{} {
+	c := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		c[k] = v
+	}
+	return c
+}
+
+// Session holds the values of the current request's session.
+type Session struct {
+	mu        sync.Mutex
+	id        string
+	values    map[string]interface{}
+	isNew     bool
+	modified  bool
+	destroyed bool
+}
+
+// ID returns the session ID.
+func (s *Session) ID() string {
+	return s.id
+}
+
+// Get returns the value stored under key, or nil if there is none.
+func (s *Session) Get(key string) interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.values[key]
+}
+
+// Set stores value under key.
+func (s *Session) Set(key string, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.values[key] = value
+	s.modified = true
+}
+
+// Delete removes the value stored under key.
+func (s *Session) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.values, key)
+	s.modified = true
+}
+
+// Destroy removes the session from the store and expires its cookie.
+func (s *Session) Destroy() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.values = make(map[string]interface{})
+	s.destroyed = true
+}
+
+// Get returns the session for the request.
+// If the session middleware is not installed, an empty session that is
+// never persisted is returned.
+//
+// Example:
+//
+//	sess := session.Get(r)
+//	sess.Set("user_id", user.ID)
+func Get(r *http.Request) *Session {
+	if s, ok := ctxval.Get[*Session](r); ok {
+		return s
+	}
+	return &Session{values: make(map[string]interface{})}
+}
+
+// Config holds the configuration for session middleware.
+type Config struct {
+	cookieName string
+	path       string
+	maxAge     int
+	secure     bool
+	sameSite   http.SameSite
+}
+
+// Option defines a function that configures session options.
+type Option func(*Config)
+
+// WithCookieName sets the name of the session cookie.
+func WithCookieName(name string) Option {
+	return func(c *Config) {
+		c.cookieName = name
+	}
+}
+
+// WithPath sets the Path attribute of the session cookie. The default is "/".
+func WithPath(path string) Option {
+	return func(c *Config) {
+		c.path = path
+	}
+}
+
+// WithMaxAge sets the Max-Age attribute of the session cookie in seconds.
+// By default the cookie expires when the browser is closed.
+func WithMaxAge(seconds int) Option {
+	return func(c *Config) {
+		c.maxAge = seconds
+	}
+}
+
+// WithSecure sets the Secure attribute of the session cookie.
+func WithSecure(secure bool) Option {
+	return func(c *Config) {
+		c.secure = secure
+	}
+}
+
+// WithSameSite sets the SameSite attribute of the session cookie. The default is Lax.
+func WithSameSite(sameSite http.SameSite) Option {
+	return func(c *Config) {
+		c.sameSite = sameSite
+	}
+}
+
+// Middleware returns a middleware that loads the session referenced by the
+// session cookie from store, exposes it through Get, and saves it back to
+// the store after the handler runs if it was modified.
+//
+// The session cookie is written just before the response headers are sent,
+// so a new session must be modified before the handler writes its response.
+func Middleware(store Store, options ...Option) func(next http.Handler) http.Handler {
+	cfg := &Config{
+		cookieName: DefaultCookieName,
+		path:       "/",
+		sameSite:   http.SameSiteLaxMode,
+	}
+
+	for _, option := range options {
+		option(cfg)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return httpx.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+			sess, err := load(r, store, cfg.cookieName)
+			if err != nil {
+				return err
+			}
+
+			sw := &sessionWriter{ResponseWriter: w}
+			sw.commit = func() {
+				sess.mu.Lock()
+				defer sess.mu.Unlock()
+
+				switch {
+				case sess.destroyed:
+					http.SetCookie(w, cfg.cookie(sess.id, -1))
+				case sess.isNew && sess.modified:
+					http.SetCookie(w, cfg.cookie(sess.id, cfg.maxAge))
+				}
+			}
+
+			next.ServeHTTP(sw, ctxval.Set(r, sess))
+			sw.commitOnce()
+
+			return save(r.Context(), store, sess)
+		})
+	}
+}
+
+// load returns the session referenced by the request cookie, or a new
+// session if there is none.
+func load(r *http.Request, store Store, cookieName string) (*Session, error) {
+	if c, err := r.Cookie(cookieName); err == nil && c.Value != "" {
+		values, err := store.Load(r.Context(), c.Value)
+		if err == nil {
+			return &Session{id: c.Value, values: values}, nil
+		}
+		if !errors.Is(err, ErrNotFound) {
+			return nil, fmt.Errorf("failed to load session: %w", err)
+		}
+	}
+
+	id, err := newID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+	return &Session{id: id, values: make(map[string]interface{}), isNew: true}, nil
+}
+
+// save persists or deletes the session according to its state.
+func save(ctx context.Context, store Store, sess *Session) error {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	switch {
+	case sess.destroyed:
+		if err := store.Delete(ctx, sess.id); err != nil {
+			return fmt.Errorf("failed to delete session: %w", err)
+		}
+	case sess.modified:
+		if err := store.Save(ctx, sess.id, sess.values); err != nil {
+			return fmt.Errorf("failed to save session: %w", err)
+		}
+	}
+	return nil
+}
+
+// newID returns a random, URL-safe session ID.
+func newID() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// cookie builds the session cookie for id.
+func (c *Config) cookie(id string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     c.cookieName,
+		Value:    id,
+		Path:     c.path,
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   c.secure,
+		SameSite: c.sameSite,
+	}
+}
+
+// sessionWriter writes the session cookie just before the response headers are sent.
+type sessionWriter struct {
+	http.ResponseWriter
+	commit    func()
+	committed bool
+}
+
+func (w *sessionWriter) commitOnce() {
+	if !w.committed {
+		w.committed = true
+		w.commit()
+	}
+}
+
+// WriteHeader commits the session cookie and writes the status code.
+func (w *sessionWriter) WriteHeader(statusCode int) {
+	w.commitOnce()
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write commits the session cookie and writes the body.
+func (w *sessionWriter) Write(b []byte) (int, error) {
+	w.commitOnce()
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap returns the underlying http.ResponseWriter.
+func (w *sessionWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,86 @@
+package session_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/vibe-go/vibe/httpx"
+	"github.com/vibe-go/vibe/session"
+)
+
+func TestSession(t *testing.T) {
+	store := session.NewMemoryStore()
+
+	var got interface{}
+	handler := httpx.HandlerFunc(func(w http.ResponseWriter, r *http.Request) error {
+		switch r.URL.Path {
+		case "/login":
+			session.Get(r).Set("user", "alice")
+		case "/logout":
+			session.Get(r).Destroy()
+		default:
+			got = session.Get(r).Get("user")
+		}
+		w.WriteHeader(http.StatusOK)
+		return nil
+	})
+
+	wrapped := session.Middleware(store)(handler)
+
+	// First request stores a value and receives a session cookie
+	req := httptest.NewRequest(http.MethodGet, "/login", nil)
+	w := httptest.NewRecorder()
+	wrapped.ServeHTTP(w, req)
+
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != session.DefaultCookieName {
+		t.Fatalf("Expected session cookie, got %+v", cookies)
+	}
+	sessionCookie := cookies[0]
+
+	// Second request with the same cookie reads the value back
+	req = httptest.NewRequest(http.MethodGet, "/profile", nil)
+	req.AddCookie(sessionCookie)
+	w = httptest.NewRecorder()
+	wrapped.ServeHTTP(w, req)
+
+	if got != "alice" {
+		t.Errorf("Expected user 'alice', got %v", got)
+	}
+	if len(w.Result().Cookies()) != 0 {
+		t.Errorf("Expected no new cookie for an existing session")
+	}
+
+	// Destroying the session removes it from the store
+	req = httptest.NewRequest(http.MethodGet, "/logout", nil)
+	req.AddCookie(sessionCookie)
+	w = httptest.NewRecorder()
+	wrapped.ServeHTTP(w, req)
+
+	req = httptest.NewRequest(http.MethodGet, "/profile", nil)
+	req.AddCookie(sessionCookie)
+	w = httptest.NewRecorder()
+	wrapped.ServeHTTP(w, req)
+
+	if got != nil {
+		t.Errorf("Expected no user after logout, got %v", got)
+	}
+}
+
+func TestSessionWithoutChanges(t *testing.T) {
+	handler := httpx.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) error {
+		w.WriteHeader(http.StatusOK)
+		return nil
+	})
+
+	wrapped := session.Middleware(session.NewMemoryStore())(handler)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+	wrapped.ServeHTTP(w, req)
+
+	if len(w.Result().Cookies()) != 0 {
+		t.Errorf("Expected no session cookie for an unmodified session")
+	}
+}
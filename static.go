@@ -0,0 +1,235 @@
+package vibe
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/vibe-go/vibe/httpx"
+	"github.com/vibe-go/vibe/middleware"
+)
+
+// StaticOption configures Static.
+type StaticOption func(*staticConfig)
+
+// staticConfig holds the configuration for Static.
+type staticConfig struct {
+	listing     bool
+	apiPrefixes []string
+}
+
+// WithDirectoryListing enables or disables listing the contents of
+// directories that have no index.html. Listing is disabled by default, since
+// a directory index can leak the names of files not meant to be linked.
+func WithDirectoryListing(enabled bool) StaticOption {
+	return func(c *staticConfig) {
+		c.listing = enabled
+	}
+}
+
+// WithAPIPrefixes excludes the given URL prefixes from the SPA index
+// fallback, so that unknown API paths respond 404 Not Found instead of
+// serving the application's HTML.
+func WithAPIPrefixes(prefixes ...string) StaticOption {
+	return func(c *staticConfig) {
+		for _, prefix := range prefixes {
+			if prefix = cleanPrefix(prefix); prefix != "" {
+				c.apiPrefixes = append(c.apiPrefixes, prefix)
+			}
+		}
+	}
+}
+
+// Static serves the files under dir at the given URL prefix. Files are
+// served with http.ServeContent, so conditional and range requests are
+// supported: responses advertise "Accept-Ranges: bytes" and a Range header
+// yields a 206 Partial Content response. A directory is served through its
+// index.html, if it has one, and otherwise responds 404 Not Found unless
+// WithDirectoryListing is set.
+//
+// If a precompressed variant of a file exists next to it, e.g. app.js.gz for
+// app.js, clients that accept gzip are sent its content with
+// "Content-Encoding: gzip" instead, so that assets compressed at build time
+// are not compressed again on every request.
+//
+// Example:
+//
+//	router.Static("/assets", "./public")
+func (r *Router) Static(prefix, dir string, options ...StaticOption) {
+	cfg := &staticConfig{}
+	for _, option := range options {
+		option(cfg)
+	}
+
+	prefix = cleanPrefix(prefix)
+	root := http.Dir(dir)
+
+	var listing http.Handler
+	if cfg.listing {
+		listing = http.StripPrefix(prefix, http.FileServer(root))
+	}
+
+	r.Get(joinPattern(prefix, "/"), func(w http.ResponseWriter, req *http.Request) error {
+		name := path.Clean("/" + strings.TrimPrefix(req.URL.Path, prefix))
+		if listing != nil && isDir(root, name) {
+			listing.ServeHTTP(w, req)
+			return nil
+		}
+		return serveFile(w, req, root, name)
+	})
+}
+
+// SPA serves a single-page application from dir at the given URL prefix.
+// Paths that map to a file under dir are served like Static; every other
+// path responds 200 with indexFile, so that client-side routes can be
+// loaded directly. Paths under a prefix given with WithAPIPrefixes never
+// fall back to the index. Routes registered on the router take precedence
+// over the SPA as usual.
+//
+// Example:
+//
+//	router.SPA("/", "./dist", "index.html", vibe.WithAPIPrefixes("/api"))
+func (r *Router) SPA(prefix, dir, indexFile string, options ...StaticOption) {
+	cfg := &staticConfig{}
+	for _, option := range options {
+		option(cfg)
+	}
+
+	prefix = cleanPrefix(prefix)
+	root := http.Dir(dir)
+	index := path.Clean("/" + indexFile)
+
+	r.Get(joinPattern(prefix, "/"), func(w http.ResponseWriter, req *http.Request) error {
+		for _, apiPrefix := range cfg.apiPrefixes {
+			if req.URL.Path == apiPrefix || strings.HasPrefix(req.URL.Path, apiPrefix+"/") {
+				return httpx.NotFound(w, nil)
+			}
+		}
+
+		name := path.Clean("/" + strings.TrimPrefix(req.URL.Path, prefix))
+		if f, info, err := openFile(root, name); err == nil {
+			f.Close()
+			if !info.IsDir() {
+				return serveFile(w, req, root, name)
+			}
+		}
+		return serveFile(w, req, root, index)
+	})
+}
+
+// isDir reports whether name in root is a directory.
+func isDir(root http.FileSystem, name string) bool {
+	f, info, err := openFile(root, name)
+	if err != nil {
+		return false
+	}
+	f.Close()
+	return info.IsDir()
+}
+
+// serveFile serves the named file from root with http.ServeContent.
+// Directories are served through their index.html.
+func serveFile(w http.ResponseWriter, req *http.Request, root http.FileSystem, name string) error {
+	f, info, err := openFile(root, name)
+	if err != nil {
+		return fileError(w, err)
+	}
+	defer f.Close()
+
+	if info.IsDir() {
+		f.Close()
+		name = path.Join(name, "index.html")
+		f, info, err = openFile(root, name)
+		if err != nil {
+			return fileError(w, err)
+		}
+		defer f.Close()
+		if info.IsDir() {
+			return httpx.NotFound(w, nil)
+		}
+	}
+
+	if gz, gzInfo, ok := precompressed(root, name, info); ok {
+		defer gz.Close()
+		middleware.AddVary(w.Header(), "Accept-Encoding")
+		if middleware.AcceptsGzip(req) {
+			if err := setContentType(w, info.Name(), f); err != nil {
+				return httpx.InternalError(w, nil)
+			}
+			w.Header().Set("Content-Encoding", "gzip")
+			http.ServeContent(w, req, info.Name(), gzInfo.ModTime(), gz)
+			return nil
+		}
+	}
+
+	http.ServeContent(w, req, info.Name(), info.ModTime(), f)
+	return nil
+}
+
+// precompressed opens the gzip-compressed variant of the file name, i.e.
+// name with ".gz" appended, if one exists next to it.
+func precompressed(root http.FileSystem, name string, info fs.FileInfo) (http.File, fs.FileInfo, bool) {
+	if info.IsDir() || strings.HasSuffix(name, ".gz") {
+		return nil, nil, false
+	}
+	gz, gzInfo, err := openFile(root, name+".gz")
+	if err != nil {
+		return nil, nil, false
+	}
+	if gzInfo.IsDir() {
+		gz.Close()
+		return nil, nil, false
+	}
+	return gz, gzInfo, true
+}
+
+// setContentType sets the Content-Type for the file name, from its
+// extension or else from sniffing the start of f, as http.ServeContent
+// would for the uncompressed file.
+func setContentType(w http.ResponseWriter, name string, f io.ReadSeeker) error {
+	if w.Header().Get("Content-Type") != "" {
+		return nil
+	}
+	ctype := mime.TypeByExtension(filepath.Ext(name))
+	if ctype == "" {
+		var buf [512]byte
+		n, _ := io.ReadFull(f, buf[:])
+		ctype = http.DetectContentType(buf[:n])
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+	}
+	w.Header().Set("Content-Type", ctype)
+	return nil
+}
+
+// openFile opens name in root and returns it with its file info.
+func openFile(root http.FileSystem, name string) (http.File, fs.FileInfo, error) {
+	f, err := root.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	return f, info, nil
+}
+
+// fileError responds to a failure to open a file without exposing its path.
+func fileError(w http.ResponseWriter, err error) error {
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return httpx.NotFound(w, nil)
+	case errors.Is(err, fs.ErrPermission):
+		return httpx.Error(w, errors.New(http.StatusText(http.StatusForbidden)), http.StatusForbidden)
+	default:
+		return httpx.InternalError(w, nil)
+	}
+}
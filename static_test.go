@@ -0,0 +1,268 @@
+package vibe_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vibe-go/vibe"
+)
+
+// writeFiles creates the given files, keyed by slash-separated path, under a
+// temporary directory and returns the directory.
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("Failed to write temp file: %v", err)
+		}
+	}
+	return dir
+}
+
+func TestStatic(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"video.txt":       "0123456789",
+		"docs/index.html": "<h1>docs</h1>",
+	})
+
+	router := vibe.New()
+	router.Static("/assets", dir)
+
+	t.Run("ServesFile", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/assets/video.txt", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+		}
+		if w.Header().Get("Accept-Ranges") != "bytes" {
+			t.Errorf("Expected Accept-Ranges 'bytes', got '%s'", w.Header().Get("Accept-Ranges"))
+		}
+		if w.Body.String() != "0123456789" {
+			t.Errorf("Expected body '0123456789', got '%s'", w.Body.String())
+		}
+	})
+
+	t.Run("RangeRequest", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/assets/video.txt", nil)
+		req.Header.Set("Range", "bytes=0-3")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusPartialContent {
+			t.Errorf("Expected status code %d, got %d", http.StatusPartialContent, w.Code)
+		}
+		if w.Header().Get("Content-Range") != "bytes 0-3/10" {
+			t.Errorf("Expected Content-Range 'bytes 0-3/10', got '%s'", w.Header().Get("Content-Range"))
+		}
+		if w.Body.String() != "0123" {
+			t.Errorf("Expected body '0123', got '%s'", w.Body.String())
+		}
+	})
+
+	t.Run("DirectoryIndex", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/assets/docs/", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+		}
+		if w.Body.String() != "<h1>docs</h1>" {
+			t.Errorf("Expected index body, got '%s'", w.Body.String())
+		}
+	})
+
+	t.Run("MissingFile", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/assets/missing.txt", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status code %d, got %d", http.StatusNotFound, w.Code)
+		}
+	})
+}
+
+func TestStaticDirectoryListing(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"private/secret.txt": "secret",
+	})
+
+	t.Run("DisabledByDefault", func(t *testing.T) {
+		router := vibe.New()
+		router.Static("/files", dir)
+
+		for _, path := range []string{"/files/", "/files/private", "/files/private/"} {
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusNotFound {
+				t.Errorf("Expected status code %d for %s, got %d", http.StatusNotFound, path, w.Code)
+			}
+			if strings.Contains(w.Body.String(), "secret.txt") {
+				t.Errorf("Expected directory contents of %s not to be listed", path)
+			}
+		}
+	})
+
+	t.Run("Enabled", func(t *testing.T) {
+		router := vibe.New()
+		router.Static("/files", dir, vibe.WithDirectoryListing(true))
+
+		req := httptest.NewRequest(http.MethodGet, "/files/private/", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+		}
+		if !strings.Contains(w.Body.String(), "secret.txt") {
+			t.Errorf("Expected listing to contain 'secret.txt', got '%s'", w.Body.String())
+		}
+	})
+}
+
+func TestFileRange(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"clip.txt": "abcdefgh"})
+
+	router := vibe.New()
+	router.File("/clip", filepath.Join(dir, "clip.txt"))
+
+	req := httptest.NewRequest(http.MethodGet, "/clip", nil)
+	req.Header.Set("Range", "bytes=0-3")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusPartialContent {
+		t.Errorf("Expected status code %d, got %d", http.StatusPartialContent, w.Code)
+	}
+	if w.Body.String() != "abcd" {
+		t.Errorf("Expected body 'abcd', got '%s'", w.Body.String())
+	}
+}
+
+func TestSPA(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"index.html": "<div id=\"app\"></div>",
+		"app.js":     "console.log('app')",
+	})
+
+	router := vibe.New()
+	router.Get("/api/users", func(w http.ResponseWriter, _ *http.Request) error {
+		_, err := w.Write([]byte("users"))
+		return err
+	})
+	router.SPA("/", dir, "index.html", vibe.WithAPIPrefixes("/api"))
+
+	tests := []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{"/", http.StatusOK, "<div id=\"app\"></div>"},
+		{"/some/client/route", http.StatusOK, "<div id=\"app\"></div>"},
+		{"/app.js", http.StatusOK, "console.log('app')"},
+		{"/api/users", http.StatusOK, "users"},
+		{"/api/unknown", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Errorf("Expected status code %d, got %d", tt.status, w.Code)
+			}
+			if tt.body != "" && w.Body.String() != tt.body {
+				t.Errorf("Expected body '%s', got '%s'", tt.body, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestStaticPrecompressed(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"app.js":        "console.log('app')",
+		"app.js.gz":     "gzipped app",
+		"style.css":     "body {}",
+		"index.html":    "<h1>home</h1>",
+		"index.html.gz": "gzipped home",
+	})
+
+	router := vibe.New()
+	router.Static("/assets", dir)
+
+	tests := []struct {
+		name           string
+		path           string
+		acceptEncoding string
+		body           string
+		encoding       string
+		contentType    string
+	}{
+		{"AcceptsGzip", "/assets/app.js", "gzip, deflate", "gzipped app", "gzip", "text/javascript; charset=utf-8"},
+		{"NoAcceptEncoding", "/assets/app.js", "", "console.log('app')", "", "text/javascript; charset=utf-8"},
+		{"GzipRefused", "/assets/app.js", "gzip;q=0", "console.log('app')", "", "text/javascript; charset=utf-8"},
+		{"NoGzVariant", "/assets/style.css", "gzip", "body {}", "", "text/css; charset=utf-8"},
+		{"Index", "/assets/", "gzip", "gzipped home", "gzip", "text/html; charset=utf-8"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.acceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+			}
+			if w.Body.String() != tt.body {
+				t.Errorf("Expected body '%s', got '%s'", tt.body, w.Body.String())
+			}
+			if got := w.Header().Get("Content-Encoding"); got != tt.encoding {
+				t.Errorf("Expected Content-Encoding '%s', got '%s'", tt.encoding, got)
+			}
+			if got := w.Header().Get("Content-Type"); got != tt.contentType {
+				t.Errorf("Expected Content-Type '%s', got '%s'", tt.contentType, got)
+			}
+		})
+	}
+
+	t.Run("Vary", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/assets/app.js", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if got := w.Header().Get("Vary"); got != "Accept-Encoding" {
+			t.Errorf("Expected Vary 'Accept-Encoding', got '%s'", got)
+		}
+	})
+}
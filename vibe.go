@@ -41,16 +41,26 @@
 package vibe
 
 import (
+	"context"
 	"encoding/json"
 	"log"
 	"net/http"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/vibe-go/vibe/httpx"
 	"github.com/vibe-go/vibe/middleware"
 )
 
+// DefaultTimeout is the timeout applied by the router's default timeout
+// middleware unless WithTimeout or WithoutTimeout is given.
+const DefaultTimeout = 60 * time.Second
+
 // MiddlewareFunc follows the standard http middleware pattern in Go.
 type MiddlewareFunc func(http.Handler) http.Handler
 
@@ -67,7 +77,7 @@ func WithoutRecovery() RouterOption {
 }
 
 // WithoutTimeout disables the default timeout middleware.
-// By default, the router includes a timeout middleware with 60-second timeout.
+// By default, the router includes a timeout middleware with DefaultTimeout.
 func WithoutTimeout() RouterOption {
 	return func(r *Router) {
 		r.disableTimeout = true
@@ -75,27 +85,75 @@ func WithoutTimeout() RouterOption {
 }
 
 // WithTimeout sets a custom timeout duration for the default timeout middleware.
-// By default, the router uses a 60-second timeout if timeout middleware is enabled.
+// By default, the router uses DefaultTimeout if timeout middleware is enabled.
 func WithTimeout(duration time.Duration) RouterOption {
 	return func(r *Router) {
 		r.timeout = duration
 	}
 }
 
+// WithDefaultErrorStatus sets the status used for errors returned by
+// handlers that do not carry a status of their own, e.g. 400 Bad Request for
+// input-heavy APIs. Errors wrapping an *httpx.StatusError keep their status.
+// By default such errors are reported as 500 Internal Server Error.
+//
+// Example:
+//
+//	router := vibe.New(vibe.WithDefaultErrorStatus(http.StatusBadRequest))
+func WithDefaultErrorStatus(status int) RouterOption {
+	return func(r *Router) {
+		r.errorStatus = status
+	}
+}
+
+// WithJSONNotFound makes unmatched routes respond with a JSON 404 body of the
+// form {"error":"not found","path":"..."} instead of the standard library's
+// plain-text "404 page not found".
+func WithJSONNotFound() RouterOption {
+	return func(r *Router) {
+		r.notFound = r.newEntry(httpx.HandlerFunc(jsonNotFound))
+		r.handleUnmatched()
+	}
+}
+
+// jsonNotFound writes the JSON 404 response used by WithJSONNotFound.
+func jsonNotFound(w http.ResponseWriter, req *http.Request) error {
+	return httpx.JSON(w, map[string]string{
+		"error": "not found",
+		"path":  req.URL.Path,
+	}, http.StatusNotFound)
+}
+
+// WithMiddlewareCapacity preallocates room for n global middlewares, so that
+// routers adding many of them with Use do not repeatedly grow the slice.
+// Middleware added by New, such as recovery and timeout, counts towards n.
+func WithMiddlewareCapacity(n int) RouterOption {
+	return func(r *Router) {
+		r.middlewares = make([]MiddlewareFunc, 0, n)
+	}
+}
+
 // Router wraps the standard library ServeMux and adds middleware and method-specific route registration.
 // It provides a more expressive API for defining routes and applying middleware.
 type Router struct {
-	mux             *http.ServeMux
-	middlewares     []MiddlewareFunc
-	logger          *log.Logger
-	disableRecovery bool
-	disableTimeout  bool
-	timeout         time.Duration
+	mux              *http.ServeMux
+	mu               sync.RWMutex // guards middlewares
+	middlewares      []MiddlewareFunc
+	version          atomic.Uint64 // incremented whenever middlewares changes
+	routes           []Route
+	notFound         *routeEntry
+	methodNotAllowed *routeEntry
+	fallback         bool // the "/" catch-all serving unmatched requests is registered
+	logger           *log.Logger
+	disableRecovery  bool
+	disableTimeout   bool
+	timeout          time.Duration
+	errorStatus      int
 }
 
 // New creates a new Router instance with default configuration.
 // By default, it includes a recovery middleware to handle panics and
-// a timeout middleware with a 60-second timeout.
+// a timeout middleware with DefaultTimeout (60 seconds).
 // Options can be provided to customize the router's behavior.
 //
 // Example:
@@ -112,18 +170,25 @@ type Router struct {
 //	// Router without timeout middleware
 //	router := vibe.New(vibe.WithoutTimeout())
 func New(options ...RouterOption) *Router {
-	const timeout = 60 * time.Second
-
 	router := &Router{
 		mux:     http.NewServeMux(),
 		logger:  log.New(os.Stdout, "[vibe] ", log.LstdFlags),
-		timeout: timeout,
+		timeout: DefaultTimeout,
 	}
 
 	for _, option := range options {
 		option(router)
 	}
 
+	if router.errorStatus != 0 {
+		status := router.errorStatus
+		router.Use(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				next.ServeHTTP(w, req.WithContext(httpx.ContextWithDefaultStatus(req.Context(), status)))
+			})
+		})
+	}
+
 	if !router.disableRecovery {
 		router.Use(middleware.Recovery(router.logger))
 	}
@@ -136,9 +201,63 @@ func New(options ...RouterOption) *Router {
 }
 
 // Use adds a global middleware to the router.
-// Global middlewares are applied to all routes.
+// Global middlewares are applied to all routes, including routes registered
+// before Use is called. They run in the order they were added, before any
+// group or route middleware.
 func (r *Router) Use(mw MiddlewareFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	r.middlewares = append(r.middlewares, mw)
+	r.version.Add(1)
+}
+
+// routeEntry holds a registered handler together with its route middleware.
+// The full chain, including global middleware, is built when a request is
+// dispatched and cached until the global middleware changes.
+type routeEntry struct {
+	router  *Router
+	handler http.Handler
+	mws     []MiddlewareFunc
+	name    string // set with Name
+	chain   atomic.Pointer[builtChain]
+}
+
+// builtChain is a middleware chain built for a given global middleware version.
+type builtChain struct {
+	version uint64
+	handler http.Handler
+}
+
+// newEntry creates a route entry for handler with the given route middleware.
+func (r *Router) newEntry(handler http.Handler, mws ...MiddlewareFunc) *routeEntry {
+	return &routeEntry{
+		router:  r,
+		handler: handler,
+		mws:     slices.Clone(mws),
+	}
+}
+
+// ServeHTTP dispatches the request through the global and route middleware.
+func (e *routeEntry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if e.name != "" {
+		req = req.WithContext(context.WithValue(req.Context(), routeNameKey{}, e.name))
+	}
+
+	version := e.router.version.Load()
+	if c := e.chain.Load(); c != nil && c.version == version {
+		c.handler.ServeHTTP(w, req)
+		return
+	}
+
+	e.router.mu.RLock()
+	mws := slices.Concat(e.router.middlewares, e.mws)
+	version = e.router.version.Load()
+	e.router.mu.RUnlock()
+
+	h := chainMiddleware(e.handler, mws...)
+	e.chain.Store(&builtChain{version: version, handler: h})
+	h.ServeHTTP(w, req)
 }
 
 // chainMiddleware chains a list of middlewares with the base handler.
@@ -151,12 +270,41 @@ func chainMiddleware(h http.Handler, mws ...MiddlewareFunc) http.Handler {
 	return h
 }
 
+// Route describes a registered route.
+type Route struct {
+	Method  string
+	Pattern string
+	Name    string // set with Name
+}
+
 // registerRoute is a helper that registers a route with the given HTTP method and pattern.
-func (r *Router) registerRoute(method, pattern string, handler httpx.HandlerFunc, mws ...MiddlewareFunc) {
-	// Chain the handler with middlewares
-	chainedHandler := chainMiddleware(handler, append(r.middlewares, mws...)...)
+// The middleware chain is built when the route is first dispatched, so that
+// Use affects routes registered earlier.
+func (r *Router) registerRoute(method, pattern string, handler httpx.HandlerFunc, config routeConfig) {
+	entry := r.newEntry(handler, config.mws...)
+	entry.name = config.name
+	r.mux.Handle(method+" "+pattern, entry)
+	r.routes = append(r.routes, Route{Method: method, Pattern: pattern, Name: config.name})
+}
 
-	r.mux.Handle(method+" "+pattern, chainedHandler)
+// Handle registers a route for the given HTTP method, configured with
+// route options. Middleware can be passed among the options.
+//
+// Example:
+//
+//	router.Handle(http.MethodGet, "/users/{id}", getUser,
+//	    vibe.Name("get_user"),
+//	    vibe.WithRouteTimeout(5*time.Second),
+//	    requireAuth,
+//	)
+func (r *Router) Handle(method, pattern string, handler httpx.HandlerFunc, opts ...any) {
+	r.registerRoute(method, pattern, handler, newRouteConfig(opts))
+}
+
+// Routes returns the routes registered on the router, in registration order.
+// Routes registered through groups are reported with their full pattern.
+func (r *Router) Routes() []Route {
+	return slices.Clone(r.routes)
 }
 
 // ServeHTTP implements the http.Handler interface.
@@ -165,6 +313,71 @@ func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	r.mux.ServeHTTP(w, req)
 }
 
+// handleUnmatched registers a catch-all "/" pattern on the mux, once, so
+// that requests no route matches reach unmatched. Until a custom NotFound or
+// MethodNotAllowed handler is set, the mux's own responses are used.
+func (r *Router) handleUnmatched() {
+	if !r.fallback {
+		r.fallback = true
+		r.mux.Handle("/", http.HandlerFunc(r.unmatched))
+	}
+}
+
+// unmatched serves requests that match no route, with the NotFound or
+// MethodNotAllowed handler if set and as the mux would otherwise.
+func (r *Router) unmatched(w http.ResponseWriter, req *http.Request) {
+	allowed := r.allowedMethods(req)
+	switch {
+	case len(allowed) > 0:
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		if r.methodNotAllowed != nil {
+			r.methodNotAllowed.ServeHTTP(w, req)
+			return
+		}
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	case r.notFound != nil:
+		r.notFound.ServeHTTP(w, req)
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+// allowedMethods returns the methods of the routes matching the request's
+// path, regardless of the request's own method.
+func (r *Router) allowedMethods(req *http.Request) []string {
+	var allowed []string
+	probe := *req
+	for _, method := range routeMethods {
+		probe.Method = method
+		if _, pattern := r.mux.Handler(&probe); pattern != "" && pattern != "/" {
+			allowed = append(allowed, method)
+		}
+	}
+	return allowed
+}
+
+// routeMethods lists the methods routes can be registered with.
+var routeMethods = []string{
+	http.MethodGet,
+	http.MethodHead,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+	http.MethodOptions,
+}
+
+// Mux returns the underlying ServeMux, for registering patterns that the
+// method helpers do not cover, such as method-less catch-alls.
+//
+// Handlers registered directly on the mux bypass the router: neither global
+// nor group middleware is applied to them, and they are not reported by Routes.
+// Once NotFound or MethodNotAllowed is set, the router serves the "/"
+// pattern itself, so it cannot be registered on the mux.
+func (r *Router) Mux() *http.ServeMux {
+	return r.mux
+}
+
 // JSON sets the Content-Type to "application/json" and encodes the data as JSON.
 // It's a convenience method for returning JSON responses.
 func (r *Router) JSON(w http.ResponseWriter, data interface{}) error {
@@ -174,20 +387,34 @@ func (r *Router) JSON(w http.ResponseWriter, data interface{}) error {
 
 // Get registers a GET route.
 // The pattern supports path parameters in the format "/{param}".
-func (r *Router) Get(pattern string, handler httpx.HandlerFunc, mws ...MiddlewareFunc) {
-	r.registerRoute(http.MethodGet, pattern, handler, mws...)
+func (r *Router) Get(pattern string, handler httpx.HandlerFunc, opts ...any) {
+	r.registerRoute(http.MethodGet, pattern, handler, newRouteConfig(opts))
 }
 
 // Post registers a POST route.
 // The pattern supports path parameters in the format "/{param}".
-func (r *Router) Post(pattern string, handler httpx.HandlerFunc, mws ...MiddlewareFunc) {
-	r.registerRoute(http.MethodPost, pattern, handler, mws...)
+func (r *Router) Post(pattern string, handler httpx.HandlerFunc, opts ...any) {
+	r.registerRoute(http.MethodPost, pattern, handler, newRouteConfig(opts))
 }
 
 // Put registers a PUT route.
 // The pattern supports path parameters in the format "/{param}".
-func (r *Router) Put(pattern string, handler httpx.HandlerFunc, mws ...MiddlewareFunc) {
-	r.registerRoute(http.MethodPut, pattern, handler, mws...)
+func (r *Router) Put(pattern string, handler httpx.HandlerFunc, opts ...any) {
+	r.registerRoute(http.MethodPut, pattern, handler, newRouteConfig(opts))
+}
+
+// File registers a GET route that serves the single file at path.
+// The Content-Type is derived from the file extension and Last-Modified is
+// set so clients can make conditional requests. Range requests are
+// supported, as with Static.
+//
+// Example:
+//
+//	router.File("/favicon.ico", "./static/favicon.ico")
+func (r *Router) File(pattern, path string, opts ...any) {
+	r.Get(pattern, func(w http.ResponseWriter, req *http.Request) error {
+		return serveFile(w, req, http.Dir(filepath.Dir(path)), "/"+filepath.Base(path))
+	}, opts...)
 }
 
 // Group represents a group of routes with a common prefix and middleware.
@@ -196,22 +423,51 @@ type Group struct {
 	router     *Router
 	prefix     string
 	middleware []MiddlewareFunc
+	inherited  int // number of leading middleware inherited from the parent group
 }
 
 // Group creates a new route group with the given prefix.
 // All routes registered on the group will have the specified path prefix.
 // Additional middleware can be applied to all routes in the group.
 //
+// Patterns registered on the group are joined to the prefix with a single
+// slash. An empty pattern registers the prefix itself, while "/" registers
+// the prefix with a trailing slash, which ServeMux treats as a subtree.
+//
 // Example:
 //
 //	api := router.Group("/api/v1")
-//	api.Get("/users", listUsers)
+//	api.Get("/users", listUsers)  // Route: /api/v1/users
+//	api.Get("", apiIndex)         // Route: /api/v1
+//	api.Get("/", apiTree)         // Route: /api/v1/ (and everything below it)
 func (r *Router) Group(prefix string, mws ...MiddlewareFunc) *Group {
 	return &Group{
 		router:     r,
-		prefix:     prefix,
-		middleware: mws,
+		prefix:     cleanPrefix(prefix),
+		middleware: slices.Clone(mws),
+	}
+}
+
+// cleanPrefix normalizes a group prefix to either "" or a path with a single
+// leading slash and no trailing slash, so that prefixes can be joined safely.
+func cleanPrefix(prefix string) string {
+	prefix = strings.Trim(prefix, "/")
+	if prefix == "" {
+		return ""
 	}
+	return "/" + prefix
+}
+
+// joinPattern joins a cleaned group prefix and a route pattern with exactly
+// one slash between them. A trailing slash on the pattern is preserved.
+func joinPattern(prefix, pattern string) string {
+	if pattern == "" {
+		if prefix == "" {
+			return "/"
+		}
+		return prefix
+	}
+	return prefix + "/" + strings.TrimLeft(pattern, "/")
 }
 
 // Use adds middleware to the group.
@@ -222,53 +478,99 @@ func (g *Group) Use(mw MiddlewareFunc) *Group {
 	return g
 }
 
+// Produces sets the default response Content-Type for routes in the group.
+// Handlers that set a Content-Type themselves, such as through httpx.JSON,
+// override it. Like Use, it applies to routes registered after it is called.
+// Returns the group for method chaining.
+//
+// Example:
+//
+//	feeds := router.Group("/rss").Produces("application/rss+xml")
+func (g *Group) Produces(contentType string) *Group {
+	return g.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if w.Header().Get("Content-Type") == "" {
+				w.Header().Set("Content-Type", contentType)
+			}
+			next.ServeHTTP(w, req)
+		})
+	})
+}
+
+// Deprecated marks the routes in the group as deprecated by setting the
+// "Deprecation: true" header and a Sunset header announcing sunset, the time
+// after which the routes may stop working, on all of their responses. Like
+// Use, it applies to routes registered after it is called. Returns the group
+// for method chaining.
+//
+// Example:
+//
+//	v1 := router.Group("/v1").Deprecated(time.Date(2026, 6, 30, 0, 0, 0, 0, time.UTC))
+func (g *Group) Deprecated(sunset time.Time) *Group {
+	value := sunset.UTC().Format(http.TimeFormat)
+	return g.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set("Deprecation", "true")
+			w.Header().Set("Sunset", value)
+			next.ServeHTTP(w, req)
+		})
+	})
+}
+
+// Handle registers a route for the given HTTP method in the group,
+// configured with route options. The pattern is relative to the group's prefix.
+func (g *Group) Handle(method, pattern string, handler httpx.HandlerFunc, opts ...any) {
+	fullPath := joinPattern(g.prefix, pattern)
+	g.router.Handle(method, fullPath, handler, withMiddleware(g.middleware, opts)...)
+}
+
 // Get registers a GET route in the group.
 // The pattern is relative to the group's prefix.
-func (g *Group) Get(pattern string, handler httpx.HandlerFunc, mws ...MiddlewareFunc) {
-	fullPath := g.prefix + pattern
-	g.router.Get(fullPath, handler, append(g.middleware, mws...)...)
+func (g *Group) Get(pattern string, handler httpx.HandlerFunc, opts ...any) {
+	fullPath := joinPattern(g.prefix, pattern)
+	g.router.Get(fullPath, handler, withMiddleware(g.middleware, opts)...)
 }
 
 // Post registers a POST route in the group.
 // The pattern is relative to the group's prefix.
-func (g *Group) Post(pattern string, handler httpx.HandlerFunc, mws ...MiddlewareFunc) {
-	fullPath := g.prefix + pattern
-	g.router.Post(fullPath, handler, append(g.middleware, mws...)...)
+func (g *Group) Post(pattern string, handler httpx.HandlerFunc, opts ...any) {
+	fullPath := joinPattern(g.prefix, pattern)
+	g.router.Post(fullPath, handler, withMiddleware(g.middleware, opts)...)
 }
 
 // Put registers a PUT route in the group.
 // The pattern is relative to the group's prefix.
-func (g *Group) Put(pattern string, handler httpx.HandlerFunc, mws ...MiddlewareFunc) {
-	fullPath := g.prefix + pattern
-	g.router.Put(fullPath, handler, append(g.middleware, mws...)...)
+func (g *Group) Put(pattern string, handler httpx.HandlerFunc, opts ...any) {
+	fullPath := joinPattern(g.prefix, pattern)
+	g.router.Put(fullPath, handler, withMiddleware(g.middleware, opts)...)
 }
 
 // Delete registers a DELETE route in the group.
 // The pattern is relative to the group's prefix.
-func (g *Group) Delete(pattern string, handler httpx.HandlerFunc, mws ...MiddlewareFunc) {
-	fullPath := g.prefix + pattern
-	g.router.Delete(fullPath, handler, append(g.middleware, mws...)...)
+func (g *Group) Delete(pattern string, handler httpx.HandlerFunc, opts ...any) {
+	fullPath := joinPattern(g.prefix, pattern)
+	g.router.Delete(fullPath, handler, withMiddleware(g.middleware, opts)...)
 }
 
 // Patch registers a PATCH route in the group.
 // The pattern is relative to the group's prefix.
-func (g *Group) Patch(pattern string, handler httpx.HandlerFunc, mws ...MiddlewareFunc) {
-	fullPath := g.prefix + pattern
-	g.router.Patch(fullPath, handler, append(g.middleware, mws...)...)
+func (g *Group) Patch(pattern string, handler httpx.HandlerFunc, opts ...any) {
+	fullPath := joinPattern(g.prefix, pattern)
+	g.router.Patch(fullPath, handler, withMiddleware(g.middleware, opts)...)
 }
 
 // Options registers an OPTIONS route in the group.
 // The pattern is relative to the group's prefix.
-func (g *Group) Options(pattern string, handler httpx.HandlerFunc, mws ...MiddlewareFunc) {
-	fullPath := g.prefix + pattern
-	g.router.Options(fullPath, handler, append(g.middleware, mws...)...)
+func (g *Group) Options(pattern string, handler httpx.HandlerFunc, opts ...any) {
+	fullPath := joinPattern(g.prefix, pattern)
+	g.router.Options(fullPath, handler, withMiddleware(g.middleware, opts)...)
 }
 
 // Head registers a HEAD route in the group.
 // The pattern is relative to the group's prefix.
-func (g *Group) Head(pattern string, handler httpx.HandlerFunc, mws ...MiddlewareFunc) {
-	fullPath := g.prefix + pattern
-	g.router.Head(fullPath, handler, append(g.middleware, mws...)...)
+func (g *Group) Head(pattern string, handler httpx.HandlerFunc, opts ...any) {
+	fullPath := joinPattern(g.prefix, pattern)
+	g.router.Head(fullPath, handler, withMiddleware(g.middleware, opts)...)
 }
 
 // Group creates a sub-group with the given prefix.
@@ -281,36 +583,126 @@ func (g *Group) Head(pattern string, handler httpx.HandlerFunc, mws ...Middlewar
 //	admin := api.Group("/admin")
 //	admin.Get("/stats", getStats)  // Route: /api/v1/admin/stats
 func (g *Group) Group(prefix string, mws ...MiddlewareFunc) *Group {
-	fullPrefix := g.prefix + prefix
+	fullPrefix := g.prefix + cleanPrefix(prefix)
 	return &Group{
 		router:     g.router,
 		prefix:     fullPrefix,
-		middleware: append(g.middleware, mws...),
+		middleware: slices.Concat(g.middleware, mws),
+		inherited:  len(g.middleware),
 	}
 }
 
+// WithoutInherited returns a copy of the group that keeps its prefix and its
+// own middleware but drops the middleware inherited from parent groups.
+// Global router middleware still applies.
+//
+// Example:
+//
+//	api := router.Group("/api", rateLimit)
+//	internal := api.Group("/internal", requireToken).WithoutInherited()
+//	internal.Get("/health", health)  // runs requireToken but not rateLimit
+func (g *Group) WithoutInherited() *Group {
+	return &Group{
+		router:     g.router,
+		prefix:     g.prefix,
+		middleware: slices.Clone(g.middleware[g.inherited:]),
+	}
+}
+
+// Scope applies a set of middleware to the routes registered through it,
+// without adding a path prefix like a Group does.
+type Scope struct {
+	router     *Router
+	middleware []MiddlewareFunc
+}
+
+// With returns a Scope that applies the given middleware to every route
+// registered through it. It is useful for sharing middleware between a few
+// routes that do not share a prefix.
+//
+// Example:
+//
+//	router.With(requireAuth).Post("/posts", createPost)
+//	router.With(requireAuth, audit).Delete("/posts/{id}", deletePost)
+func (r *Router) With(mws ...MiddlewareFunc) *Scope {
+	return &Scope{
+		router:     r,
+		middleware: mws,
+	}
+}
+
+// With returns a new Scope that applies the given middleware after the
+// scope's existing middleware.
+func (s *Scope) With(mws ...MiddlewareFunc) *Scope {
+	return &Scope{
+		router:     s.router,
+		middleware: slices.Concat(s.middleware, mws),
+	}
+}
+
+// Handle registers a route for the given HTTP method in the scope,
+// configured with route options.
+func (s *Scope) Handle(method, pattern string, handler httpx.HandlerFunc, opts ...any) {
+	s.router.Handle(method, pattern, handler, withMiddleware(s.middleware, opts)...)
+}
+
+// Get registers a GET route in the scope.
+func (s *Scope) Get(pattern string, handler httpx.HandlerFunc, opts ...any) {
+	s.router.Get(pattern, handler, withMiddleware(s.middleware, opts)...)
+}
+
+// Post registers a POST route in the scope.
+func (s *Scope) Post(pattern string, handler httpx.HandlerFunc, opts ...any) {
+	s.router.Post(pattern, handler, withMiddleware(s.middleware, opts)...)
+}
+
+// Put registers a PUT route in the scope.
+func (s *Scope) Put(pattern string, handler httpx.HandlerFunc, opts ...any) {
+	s.router.Put(pattern, handler, withMiddleware(s.middleware, opts)...)
+}
+
+// Delete registers a DELETE route in the scope.
+func (s *Scope) Delete(pattern string, handler httpx.HandlerFunc, opts ...any) {
+	s.router.Delete(pattern, handler, withMiddleware(s.middleware, opts)...)
+}
+
+// Patch registers a PATCH route in the scope.
+func (s *Scope) Patch(pattern string, handler httpx.HandlerFunc, opts ...any) {
+	s.router.Patch(pattern, handler, withMiddleware(s.middleware, opts)...)
+}
+
+// Options registers an OPTIONS route in the scope.
+func (s *Scope) Options(pattern string, handler httpx.HandlerFunc, opts ...any) {
+	s.router.Options(pattern, handler, withMiddleware(s.middleware, opts)...)
+}
+
+// Head registers a HEAD route in the scope.
+func (s *Scope) Head(pattern string, handler httpx.HandlerFunc, opts ...any) {
+	s.router.Head(pattern, handler, withMiddleware(s.middleware, opts)...)
+}
+
 // Delete registers a DELETE route.
 // The pattern supports path parameters in the format "/{param}".
-func (r *Router) Delete(pattern string, handler httpx.HandlerFunc, mws ...MiddlewareFunc) {
-	r.registerRoute(http.MethodDelete, pattern, handler, mws...)
+func (r *Router) Delete(pattern string, handler httpx.HandlerFunc, opts ...any) {
+	r.registerRoute(http.MethodDelete, pattern, handler, newRouteConfig(opts))
 }
 
 // Patch registers a PATCH route.
 // The pattern supports path parameters in the format "/{param}".
-func (r *Router) Patch(pattern string, handler httpx.HandlerFunc, mws ...MiddlewareFunc) {
-	r.registerRoute(http.MethodPatch, pattern, handler, mws...)
+func (r *Router) Patch(pattern string, handler httpx.HandlerFunc, opts ...any) {
+	r.registerRoute(http.MethodPatch, pattern, handler, newRouteConfig(opts))
 }
 
 // Options registers an OPTIONS route.
 // The pattern supports path parameters in the format "/{param}".
-func (r *Router) Options(pattern string, handler httpx.HandlerFunc, mws ...MiddlewareFunc) {
-	r.registerRoute(http.MethodOptions, pattern, handler, mws...)
+func (r *Router) Options(pattern string, handler httpx.HandlerFunc, opts ...any) {
+	r.registerRoute(http.MethodOptions, pattern, handler, newRouteConfig(opts))
 }
 
 // Head registers a HEAD route.
 // The pattern supports path parameters in the format "/{param}".
-func (r *Router) Head(pattern string, handler httpx.HandlerFunc, mws ...MiddlewareFunc) {
-	r.registerRoute(http.MethodHead, pattern, handler, mws...)
+func (r *Router) Head(pattern string, handler httpx.HandlerFunc, opts ...any) {
+	r.registerRoute(http.MethodHead, pattern, handler, newRouteConfig(opts))
 }
 
 // NotFound sets a custom handler for 404 Not Found responses.
@@ -324,16 +716,26 @@ func (r *Router) Head(pattern string, handler httpx.HandlerFunc, mws ...Middlewa
 //	        "path": r.URL.Path,
 //	    }, http.StatusNotFound)
 //	})
+//
+// The handler runs, wrapped in the global middleware, whenever no route
+// matches the request path, including the root path "/" when no root route
+// is registered.
 func (r *Router) NotFound(handler httpx.HandlerFunc) {
-	// Chain the handler with global middlewares
-	chainedHandler := chainMiddleware(handler, r.middlewares...)
-
-	// Override the default NotFound handler
-	r.mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
-		// Only handle paths that aren't the root path itself
-		// This prevents the NotFound handler from handling the root path
-		if req.URL.Path != "/" {
-			chainedHandler.ServeHTTP(w, req)
-		}
-	})
+	r.notFound = r.newEntry(handler)
+	r.handleUnmatched()
+}
+
+// MethodNotAllowed sets a custom handler for 405 Method Not Allowed responses.
+// The handler runs, wrapped in the global middleware, when a route matches the
+// request path but not its method. The Allow header is set to the methods
+// registered for the path before the handler is called.
+//
+// Example:
+//
+//	router.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) error {
+//	    return httpx.Error(w, fmt.Errorf("method %s not allowed", r.Method), http.StatusMethodNotAllowed)
+//	})
+func (r *Router) MethodNotAllowed(handler httpx.HandlerFunc) {
+	r.methodNotAllowed = r.newEntry(handler)
+	r.handleUnmatched()
 }
@@ -6,12 +6,15 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/vibe-go/vibe"
 	"github.com/vibe-go/vibe/httpx"
+	"github.com/vibe-go/vibe/middleware"
 )
 
 func TestBasicRouting(t *testing.T) {
@@ -166,6 +169,87 @@ func TestRouteGroups(t *testing.T) {
 	}
 }
 
+func TestGroupPrefixNormalization(t *testing.T) {
+	router := vibe.New()
+
+	handler := func(w http.ResponseWriter, r *http.Request) error {
+		return httpx.JSON(w, map[string]string{"path": r.URL.Path}, http.StatusOK)
+	}
+
+	router.Group("/api/").Get("/users", handler)
+	router.Group("admin").Get("stats", handler)
+	router.Group("/v1/").Group("/nested/").Get("//items", handler)
+
+	for _, path := range []string{"/api/users", "/admin/stats", "/v1/nested/items"} {
+		t.Run(path, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			resp := w.Result()
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("Expected status code %d, got %d", http.StatusOK, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestGroupEmptyPattern(t *testing.T) {
+	router := vibe.New()
+
+	todos := router.Group("/todos")
+	todos.Get("", func(w http.ResponseWriter, _ *http.Request) error {
+		return httpx.JSON(w, map[string]string{"route": "root"}, http.StatusOK)
+	})
+
+	docs := router.Group("/docs")
+	docs.Get("/", func(w http.ResponseWriter, _ *http.Request) error {
+		return httpx.JSON(w, map[string]string{"route": "subtree"}, http.StatusOK)
+	})
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"/todos", "root"},
+		{"/docs/", "subtree"},
+		{"/docs/anything", "subtree"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			resp := w.Result()
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("Expected status code %d, got %d", http.StatusOK, resp.StatusCode)
+			}
+
+			body, _ := io.ReadAll(resp.Body)
+			var result map[string]string
+			json.Unmarshal(body, &result)
+
+			if result["route"] != tt.expected {
+				t.Errorf("Expected route '%s', got '%s'", tt.expected, result["route"])
+			}
+		})
+	}
+
+	// The empty pattern must not match paths below the prefix
+	req := httptest.NewRequest(http.MethodGet, "/todos/1", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status code %d, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
 func TestGroupMiddleware(t *testing.T) {
 	router := vibe.New()
 
@@ -216,6 +300,22 @@ func TestWithoutRecovery(_ *testing.T) {
 	router.ServeHTTP(w, req)
 }
 
+func TestDefaultStackRecoversPanic(t *testing.T) {
+	router := vibe.New()
+	router.Get("/panic", func(_ http.ResponseWriter, _ *http.Request) error {
+		panic("boom")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status code %d, got %d", http.StatusInternalServerError, w.Code)
+	}
+}
+
 func TestWithTimeout(t *testing.T) {
 	// Create router with a very short timeout
 	router := vibe.New(vibe.WithTimeout(50 * time.Millisecond))
@@ -236,6 +336,24 @@ func TestWithTimeout(t *testing.T) {
 	}
 }
 
+func TestWithTimeoutPropagatesStatus(t *testing.T) {
+	router := vibe.New(vibe.WithTimeout(100 * time.Millisecond))
+
+	router.Post("/items", func(w http.ResponseWriter, _ *http.Request) error {
+		return httpx.JSON(w, map[string]string{"status": "created"}, http.StatusCreated)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/items", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("Expected status code %d, got %d", http.StatusCreated, resp.StatusCode)
+	}
+}
+
 func TestWithoutTimeout(t *testing.T) {
 	router := vibe.New(vibe.WithoutTimeout())
 
@@ -305,6 +423,177 @@ func TestNotFound(t *testing.T) {
 	}
 }
 
+func TestNotFoundRootPath(t *testing.T) {
+	customNotFound := func(w http.ResponseWriter, _ *http.Request) error {
+		return httpx.JSON(w, map[string]string{"error": "custom not found"}, http.StatusNotFound)
+	}
+
+	// Test case: no root route registered
+	t.Run("Unregistered", func(t *testing.T) {
+		router := vibe.New()
+		router.NotFound(customNotFound)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status code %d, got %d", http.StatusNotFound, w.Code)
+		}
+
+		if !strings.Contains(w.Body.String(), "custom not found") {
+			t.Errorf("Expected custom not found body, got %s", w.Body.String())
+		}
+	})
+
+	// Test case: registered root route is not shadowed
+	t.Run("Registered", func(t *testing.T) {
+		router := vibe.New()
+		router.Get("/{$}", func(w http.ResponseWriter, _ *http.Request) error {
+			return httpx.JSON(w, map[string]string{"page": "home"}, http.StatusOK)
+		})
+		router.NotFound(customNotFound)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+		}
+
+		// Other paths still reach the custom handler
+		req = httptest.NewRequest(http.MethodGet, "/other", nil)
+		w = httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if !strings.Contains(w.Body.String(), "custom not found") {
+			t.Errorf("Expected custom not found body, got %s", w.Body.String())
+		}
+	})
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	router := vibe.New()
+
+	router.Get("/resource", func(w http.ResponseWriter, _ *http.Request) error {
+		return httpx.JSON(w, map[string]string{"method": "GET"}, http.StatusOK)
+	})
+
+	router.NotFound(func(w http.ResponseWriter, _ *http.Request) error {
+		return httpx.JSON(w, map[string]string{"error": "custom not found"}, http.StatusNotFound)
+	})
+
+	router.MethodNotAllowed(func(w http.ResponseWriter, _ *http.Request) error {
+		return httpx.JSON(w, map[string]string{"error": "custom method not allowed"}, http.StatusMethodNotAllowed)
+	})
+
+	req := httptest.NewRequest(http.MethodDelete, "/resource", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status code %d, got %d", http.StatusMethodNotAllowed, resp.StatusCode)
+	}
+
+	if resp.Header.Get("Allow") != "GET, HEAD" {
+		t.Errorf("Expected Allow header 'GET, HEAD', got '%s'", resp.Header.Get("Allow"))
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(body), "custom method not allowed") {
+		t.Errorf("Expected custom method not allowed body, got %s", string(body))
+	}
+
+	// Unknown paths still reach NotFound
+	req = httptest.NewRequest(http.MethodDelete, "/unknown", nil)
+	w = httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if !strings.Contains(w.Body.String(), "custom not found") {
+		t.Errorf("Expected custom not found body, got %s", w.Body.String())
+	}
+}
+
+func TestNotFoundKeepsPathValues(t *testing.T) {
+	router := vibe.New()
+
+	router.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) error {
+		return httpx.JSON(w, map[string]string{"id": r.PathValue("id"), "pattern": r.Pattern}, http.StatusOK)
+	})
+
+	router.NotFound(func(w http.ResponseWriter, _ *http.Request) error {
+		return httpx.JSON(w, map[string]string{"error": "custom not found"}, http.StatusNotFound)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	expected := `{"id":"42","pattern":"GET /users/{id}"}`
+	if strings.TrimSpace(w.Body.String()) != expected {
+		t.Errorf("Expected body %s, got %s", expected, w.Body.String())
+	}
+
+	// Without a MethodNotAllowed handler, the standard 405 is kept
+	req = httptest.NewRequest(http.MethodPost, "/users/42", nil)
+	w = httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status code %d, got %d", http.StatusMethodNotAllowed, w.Code)
+	}
+	if w.Header().Get("Allow") != "GET, HEAD" {
+		t.Errorf("Expected Allow header 'GET, HEAD', got '%s'", w.Header().Get("Allow"))
+	}
+}
+
+func TestWithJSONNotFound(t *testing.T) {
+	router := vibe.New(vibe.WithJSONNotFound())
+
+	router.Get("/exists", func(w http.ResponseWriter, _ *http.Request) error {
+		return httpx.JSON(w, map[string]string{"status": "ok"}, http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/missing", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status code %d, got %d", http.StatusNotFound, resp.StatusCode)
+	}
+
+	if resp.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("Expected Content-Type 'application/json', got '%s'", resp.Header.Get("Content-Type"))
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	expected := `{"error":"not found","path":"/missing"}`
+	if strings.TrimSpace(string(body)) != expected {
+		t.Errorf("Expected body %s, got %s", expected, string(body))
+	}
+
+	// A known path with another method is not reported as not found
+	req = httptest.NewRequest(http.MethodPost, "/exists", nil)
+	w = httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status code %d, got %d", http.StatusMethodNotAllowed, w.Code)
+	}
+}
+
 func TestMiddlewareChaining(t *testing.T) {
 	router := vibe.New()
 
@@ -349,3 +638,791 @@ func TestMiddlewareChaining(t *testing.T) {
 		t.Errorf("Expected X-Middleware-2 header to be set")
 	}
 }
+
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "favicon.ico")
+	if err := os.WriteFile(path, []byte("icon-data"), 0o600); err != nil {
+		t.Fatalf("Failed to write temp file: %v", err)
+	}
+
+	router := vibe.New()
+	router.File("/favicon.ico", path)
+
+	req := httptest.NewRequest(http.MethodGet, "/favicon.ico", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	if resp.Header.Get("Content-Type") == "" {
+		t.Errorf("Expected Content-Type header to be set")
+	}
+
+	if resp.Header.Get("Last-Modified") == "" {
+		t.Errorf("Expected Last-Modified header to be set")
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "icon-data" {
+		t.Errorf("Expected body 'icon-data', got '%s'", string(body))
+	}
+}
+
+func TestWith(t *testing.T) {
+	router := vibe.New()
+
+	headerMiddleware := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Scoped", "applied")
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	handler := func(w http.ResponseWriter, _ *http.Request) error {
+		return httpx.JSON(w, map[string]string{"status": "ok"}, http.StatusOK)
+	}
+
+	router.With(headerMiddleware).Get("/private", handler)
+	router.Get("/public", handler)
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"/private", "applied"},
+		{"/public", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			resp := w.Result()
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("Expected status code %d, got %d", http.StatusOK, resp.StatusCode)
+			}
+
+			if resp.Header.Get("X-Scoped") != tt.expected {
+				t.Errorf("Expected X-Scoped header '%s', got '%s'", tt.expected, resp.Header.Get("X-Scoped"))
+			}
+		})
+	}
+}
+
+func TestWithMiddlewareCapacity(t *testing.T) {
+	const n = 8
+
+	// Room for two rounds of n middlewares: AllocsPerRun calls the function
+	// once to warm up before measuring.
+	router := vibe.New(vibe.WithoutRecovery(), vibe.WithoutTimeout(), vibe.WithMiddlewareCapacity(2*n))
+
+	allocs := testing.AllocsPerRun(1, func() {
+		for i := 0; i < n; i++ {
+			router.Use(passThrough)
+		}
+	})
+
+	if allocs != 0 {
+		t.Errorf("Expected no allocations within the preallocated capacity, got %v", allocs)
+	}
+}
+
+func TestSiblingGroupsDoNotShareMiddleware(t *testing.T) {
+	router := vibe.New()
+
+	tag := func(name string) vibe.MiddlewareFunc {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Add("X-Chain", name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	handler := func(w http.ResponseWriter, _ *http.Request) error {
+		w.WriteHeader(http.StatusOK)
+		return nil
+	}
+
+	// The parent's middleware slice has spare capacity, so appending to it
+	// for each child must not let the children overwrite each other.
+	parent := router.Group("/p", tag("parent"))
+	parent.Use(tag("extra")).Use(tag("more"))
+	first := parent.Group("/first", tag("first"))
+	second := parent.Group("/second", tag("second"))
+	first.Get("/x", handler)
+	second.Get("/x", handler)
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"/p/first/x", "parent,extra,more,first"},
+		{"/p/second/x", "parent,extra,more,second"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			got := strings.Join(w.Header().Values("X-Chain"), ",")
+			if got != tt.expected {
+				t.Errorf("Expected chain '%s', got '%s'", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestGroupWithoutInherited(t *testing.T) {
+	router := vibe.New()
+
+	headerMiddleware := func(name string) vibe.MiddlewareFunc {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set(name, "applied")
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	handler := func(w http.ResponseWriter, _ *http.Request) error {
+		return httpx.JSON(w, map[string]string{"status": "ok"}, http.StatusOK)
+	}
+
+	api := router.Group("/api", headerMiddleware("X-Parent"))
+	api.Get("/public", handler)
+
+	internal := api.Group("/internal", headerMiddleware("X-Child")).WithoutInherited()
+	internal.Get("/health", handler)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/internal/health", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+
+	if resp.Header.Get("X-Parent") != "" {
+		t.Errorf("Expected parent middleware not to run")
+	}
+
+	if resp.Header.Get("X-Child") != "applied" {
+		t.Errorf("Expected child middleware to run")
+	}
+
+	// The parent group keeps its middleware
+	req = httptest.NewRequest(http.MethodGet, "/api/public", nil)
+	w = httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Result().Header.Get("X-Parent") != "applied" {
+		t.Errorf("Expected parent middleware to run on parent routes")
+	}
+}
+
+// problemResponder writes errors as application/problem+json. It uses the
+// legacy responder signature and is installed through httpx.AdaptResponder.
+type problemResponder struct{}
+
+func (problemResponder) Error(w http.ResponseWriter, err error, status int) error {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	return json.NewEncoder(w).Encode(map[string]interface{}{
+		"title":  http.StatusText(status),
+		"status": status,
+		"detail": err.Error(),
+	})
+}
+
+func TestGroupProduces(t *testing.T) {
+	router := vibe.New()
+
+	feed := func(w http.ResponseWriter, _ *http.Request) error {
+		_, err := w.Write([]byte("<rss></rss>"))
+		return err
+	}
+
+	rss := router.Group("/rss").Produces("application/rss+xml")
+	rss.Get("/feed", feed)
+	rss.Get("/json", func(w http.ResponseWriter, _ *http.Request) error {
+		return httpx.JSON(w, map[string]string{"ok": "yes"}, http.StatusOK)
+	})
+
+	api := router.Group("/api").Produces("application/json")
+	api.Get("/raw", func(w http.ResponseWriter, _ *http.Request) error {
+		_, err := w.Write([]byte(`{"raw":true}`))
+		return err
+	})
+
+	tests := []struct {
+		path        string
+		contentType string
+	}{
+		{"/rss/feed", "application/rss+xml"},
+		{"/rss/json", "application/json"},
+		{"/api/raw", "application/json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if got := w.Header().Get("Content-Type"); got != tt.contentType {
+				t.Errorf("Expected Content-Type '%s', got '%s'", tt.contentType, got)
+			}
+		})
+	}
+}
+
+func TestGroupDeprecated(t *testing.T) {
+	router := vibe.New()
+	ok := func(w http.ResponseWriter, _ *http.Request) error {
+		return httpx.JSON(w, map[string]string{"ok": "yes"}, http.StatusOK)
+	}
+
+	sunset := time.Date(2026, time.June, 30, 0, 0, 0, 0, time.UTC)
+	router.Group("/v1").Deprecated(sunset).Get("/users", ok)
+	router.Group("/v2").Get("/users", ok)
+
+	tests := []struct {
+		path        string
+		deprecation string
+		sunset      string
+	}{
+		{"/v1/users", "true", "Tue, 30 Jun 2026 00:00:00 GMT"},
+		{"/v2/users", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if got := w.Header().Get("Deprecation"); got != tt.deprecation {
+				t.Errorf("Expected Deprecation '%s', got '%s'", tt.deprecation, got)
+			}
+			if got := w.Header().Get("Sunset"); got != tt.sunset {
+				t.Errorf("Expected Sunset '%s', got '%s'", tt.sunset, got)
+			}
+		})
+	}
+}
+
+func TestGroupErrorResponder(t *testing.T) {
+	router := vibe.New()
+
+	failing := func(_ http.ResponseWriter, _ *http.Request) error {
+		return errors.New("boom")
+	}
+
+	notFound := func(w http.ResponseWriter, _ *http.Request) error {
+		return httpx.NotFound(w, nil)
+	}
+
+	api := router.Group("/api", middleware.Responder(httpx.AdaptResponder(problemResponder{})))
+	api.Get("/fail", failing)
+	api.Get("/missing", notFound)
+
+	admin := router.Group("/admin")
+	admin.Get("/fail", failing)
+
+	tests := []struct {
+		path        string
+		status      int
+		contentType string
+	}{
+		{"/api/fail", http.StatusInternalServerError, "application/problem+json"},
+		{"/api/missing", http.StatusNotFound, "application/problem+json"},
+		{"/admin/fail", http.StatusInternalServerError, "application/json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			resp := w.Result()
+			if resp.StatusCode != tt.status {
+				t.Errorf("Expected status code %d, got %d", tt.status, resp.StatusCode)
+			}
+
+			if resp.Header.Get("Content-Type") != tt.contentType {
+				t.Errorf("Expected Content-Type '%s', got '%s'", tt.contentType, resp.Header.Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestRoutes(t *testing.T) {
+	router := vibe.New()
+
+	handler := func(w http.ResponseWriter, _ *http.Request) error {
+		return httpx.JSON(w, map[string]string{"status": "ok"}, http.StatusOK)
+	}
+
+	router.Get("/users", handler)
+	router.Group("/api").Post("/items/{id}", handler)
+
+	routes := router.Routes()
+	expected := []vibe.Route{
+		{Method: http.MethodGet, Pattern: "/users"},
+		{Method: http.MethodPost, Pattern: "/api/items/{id}"},
+	}
+
+	if len(routes) != len(expected) {
+		t.Fatalf("Expected %d routes, got %d", len(expected), len(routes))
+	}
+
+	for i, route := range routes {
+		if route != expected[i] {
+			t.Errorf("Expected route %+v, got %+v", expected[i], route)
+		}
+	}
+}
+
+func TestMux(t *testing.T) {
+	router := vibe.New()
+
+	router.Mux().HandleFunc("/legacy/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Method", r.Method)
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		t.Run(method, func(t *testing.T) {
+			req := httptest.NewRequest(method, "/legacy/anything", nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusAccepted {
+				t.Errorf("Expected status code %d, got %d", http.StatusAccepted, w.Code)
+			}
+
+			if w.Header().Get("X-Method") != method {
+				t.Errorf("Expected X-Method '%s', got '%s'", method, w.Header().Get("X-Method"))
+			}
+		})
+	}
+}
+
+func TestUseAfterRouteRegistration(t *testing.T) {
+	router := vibe.New()
+
+	router.Get("/early", func(w http.ResponseWriter, _ *http.Request) error {
+		return httpx.JSON(w, map[string]string{"status": "ok"}, http.StatusOK)
+	})
+
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Late", "applied")
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/early", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+
+	if w.Header().Get("X-Late") != "applied" {
+		t.Errorf("Expected middleware added after registration to apply")
+	}
+}
+
+func TestGlobalMiddlewareOrdering(t *testing.T) {
+	router := vibe.New()
+
+	var order []string
+	record := func(name string) vibe.MiddlewareFunc {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				order = append(order, name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	router.Use(record("before"))
+	router.Get("/test", func(w http.ResponseWriter, _ *http.Request) error {
+		order = append(order, "handler")
+		return httpx.JSON(w, map[string]string{"status": "ok"}, http.StatusOK)
+	}, record("route"))
+	router.Use(record("after"))
+
+	for range 2 {
+		order = nil
+
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		expected := []string{"before", "after", "route", "handler"}
+		if strings.Join(order, ",") != strings.Join(expected, ",") {
+			t.Errorf("Expected order %v, got %v", expected, order)
+		}
+	}
+
+	// Middleware added once the chain has been built is still picked up
+	router.Use(record("late"))
+	order = nil
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	expected := []string{"before", "after", "late", "route", "handler"}
+	if strings.Join(order, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected order %v, got %v", expected, order)
+	}
+}
+
+// passThrough is a middleware that only calls the next handler.
+func passThrough(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r)
+	})
+}
+
+// discardWriter is a minimal http.ResponseWriter for benchmarks.
+type discardWriter struct {
+	header http.Header
+}
+
+func (d *discardWriter) Header() http.Header         { return d.header }
+func (d *discardWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (d *discardWriter) WriteHeader(int)             {}
+
+func BenchmarkServeHTTP(b *testing.B) {
+	handler := func(w http.ResponseWriter, _ *http.Request) error {
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	}
+
+	benchmarks := []struct {
+		name   string
+		router func() *vibe.Router
+	}{
+		{"Bare", func() *vibe.Router {
+			router := vibe.New(vibe.WithoutRecovery(), vibe.WithoutTimeout())
+			router.Get("/users/{id}", handler)
+			return router
+		}},
+		{"Middleware", func() *vibe.Router {
+			router := vibe.New(vibe.WithoutRecovery(), vibe.WithoutTimeout())
+			router.Use(passThrough)
+			router.Use(passThrough)
+			api := router.Group("/api", passThrough)
+			api.Get("/users/{id}", handler, passThrough)
+			return router
+		}},
+		{"Default", func() *vibe.Router {
+			router := vibe.New()
+			router.Get("/users/{id}", handler)
+			return router
+		}},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			router := bm.router()
+			path := "/users/42"
+			if bm.name == "Middleware" {
+				path = "/api/users/42"
+			}
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			w := &discardWriter{header: http.Header{}}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				router.ServeHTTP(w, req)
+			}
+		})
+	}
+}
+
+func TestErrorMiddlewareOnRouter(t *testing.T) {
+	var logged error
+	router := vibe.New()
+	router.Use(httpx.AdaptErrorMiddleware(func(next httpx.HandlerFunc) httpx.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			err := next(w, r)
+			logged = err
+			return err
+		}
+	}))
+
+	router.Get("/fail", func(_ http.ResponseWriter, _ *http.Request) error {
+		return errors.New("boom")
+	}, passThrough)
+
+	req := httptest.NewRequest(http.MethodGet, "/fail", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if logged == nil || logged.Error() != "boom" {
+		t.Errorf("Expected middleware to see error 'boom', got %v", logged)
+	}
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status code %d, got %d", http.StatusInternalServerError, w.Code)
+	}
+}
+
+func TestRouteName(t *testing.T) {
+	router := vibe.New()
+
+	var seen string
+	router.Get("/users/{id}", func(_ http.ResponseWriter, r *http.Request) error {
+		seen = vibe.RouteName(r)
+		return nil
+	}, vibe.Name("get_user"))
+
+	t.Run("Named route", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		if seen != "get_user" {
+			t.Errorf("Expected route name 'get_user', got %q", seen)
+		}
+	})
+
+	t.Run("Unnamed route", func(t *testing.T) {
+		router.Get("/health", func(_ http.ResponseWriter, r *http.Request) error {
+			seen = vibe.RouteName(r)
+			return nil
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/health", nil)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		if seen != "" {
+			t.Errorf("Expected empty route name, got %q", seen)
+		}
+	})
+}
+
+func TestHandleWithRouteOptions(t *testing.T) {
+	t.Run("Name and middleware together", func(t *testing.T) {
+		router := vibe.New()
+
+		var globalName, handlerName string
+		router.Use(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				globalName = vibe.RouteName(r)
+				next.ServeHTTP(w, r)
+			})
+		})
+
+		tag := vibe.MiddlewareFunc(func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Route", "tagged")
+				next.ServeHTTP(w, r)
+			})
+		})
+
+		router.Handle(http.MethodGet, "/users/{id}", func(_ http.ResponseWriter, r *http.Request) error {
+			handlerName = vibe.RouteName(r)
+			return nil
+		}, vibe.WithName("get_user"), tag)
+
+		req := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if handlerName != "get_user" {
+			t.Errorf("Expected handler to see route name 'get_user', got %q", handlerName)
+		}
+		if globalName != "get_user" {
+			t.Errorf("Expected global middleware to see route name 'get_user', got %q", globalName)
+		}
+		if w.Header().Get("X-Route") != "tagged" {
+			t.Errorf("Expected route middleware to run, got headers %v", w.Header())
+		}
+
+		routes := router.Routes()
+		if len(routes) != 1 || routes[0].Name != "get_user" {
+			t.Errorf("Expected Routes to report name 'get_user', got %+v", routes)
+		}
+	})
+
+	t.Run("Plain middleware func with an option", func(t *testing.T) {
+		router := vibe.New()
+
+		var name string
+		router.Get("/users/{id}", func(_ http.ResponseWriter, r *http.Request) error {
+			name = vibe.RouteName(r)
+			return nil
+		}, middleware.NoCompress(), vibe.WithName("get_user"))
+
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/42", nil))
+
+		if name != "get_user" {
+			t.Errorf("Expected route name 'get_user', got %q", name)
+		}
+	})
+
+	t.Run("Invalid option panics", func(t *testing.T) {
+		defer func() {
+			if recover() == nil {
+				t.Error("Expected registering a route with an invalid option to panic")
+			}
+		}()
+
+		vibe.New().Get("/", func(_ http.ResponseWriter, _ *http.Request) error {
+			return nil
+		}, "get_user")
+	})
+
+	t.Run("Group route with responder", func(t *testing.T) {
+		router := vibe.New()
+		api := router.Group("/api")
+
+		api.Handle(http.MethodGet, "/fail", func(_ http.ResponseWriter, _ *http.Request) error {
+			return errors.New("boom")
+		}, vibe.WithName("fail"), vibe.WithResponder(httpx.AdaptResponder(problemResponder{})))
+
+		req := httptest.NewRequest(http.MethodGet, "/api/fail", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if ct := w.Header().Get("Content-Type"); ct != "application/problem+json" {
+			t.Errorf("Expected Content-Type 'application/problem+json', got %q", ct)
+		}
+		if routes := router.Routes(); routes[0].Pattern != "/api/fail" || routes[0].Name != "fail" {
+			t.Errorf("Expected route /api/fail named 'fail', got %+v", routes[0])
+		}
+	})
+
+	t.Run("Route timeout", func(t *testing.T) {
+		router := vibe.New()
+		router.Handle(http.MethodGet, "/slow", func(_ http.ResponseWriter, r *http.Request) error {
+			<-r.Context().Done()
+			return nil
+		}, vibe.WithRouteTimeout(10*time.Millisecond))
+
+		req := httptest.NewRequest(http.MethodGet, "/slow", nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusRequestTimeout {
+			t.Errorf("Expected status code %d, got %d", http.StatusRequestTimeout, w.Code)
+		}
+	})
+}
+
+func TestOutboundContext(t *testing.T) {
+	t.Run("Carries RequestID and deadline", func(t *testing.T) {
+		router := vibe.New(vibe.WithTimeout(time.Minute))
+		router.Use(middleware.RequestID())
+
+		var id string
+		var hasDeadline bool
+		router.Get("/", func(_ http.ResponseWriter, r *http.Request) error {
+			ctx := vibe.OutboundContext(r)
+			id = middleware.RequestIDFrom(ctx)
+			_, hasDeadline = ctx.Deadline()
+			return nil
+		})
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(middleware.RequestIDHeader, "abc123")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		if id != "abc123" {
+			t.Errorf("Expected request ID 'abc123', got %q", id)
+		}
+		if !hasDeadline {
+			t.Error("Expected outbound context to carry the request deadline")
+		}
+	})
+
+	t.Run("Falls back to header", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(middleware.RequestIDHeader, "from-header")
+
+		if id := middleware.RequestIDFrom(vibe.OutboundContext(req)); id != "from-header" {
+			t.Errorf("Expected request ID 'from-header', got %q", id)
+		}
+	})
+}
+
+func TestDefaultTimeout(t *testing.T) {
+	router := vibe.New()
+
+	var remaining time.Duration
+	router.Get("/", func(_ http.ResponseWriter, r *http.Request) error {
+		deadline, ok := r.Context().Deadline()
+		if !ok {
+			t.Error("Expected the request to have a deadline")
+			return nil
+		}
+		remaining = time.Until(deadline)
+		return nil
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if remaining > vibe.DefaultTimeout || remaining < vibe.DefaultTimeout-time.Second {
+		t.Errorf("Expected a deadline of about %v, got %v", vibe.DefaultTimeout, remaining)
+	}
+}
+
+func TestWithDefaultErrorStatus(t *testing.T) {
+	router := vibe.New(vibe.WithDefaultErrorStatus(http.StatusBadRequest))
+	router.Get("/plain", func(_ http.ResponseWriter, _ *http.Request) error {
+		return errors.New("invalid filter")
+	})
+	router.Get("/typed", func(_ http.ResponseWriter, _ *http.Request) error {
+		return httpx.NewStatusError(http.StatusConflict, errors.New("already exists"))
+	})
+
+	tests := []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{"/plain", http.StatusBadRequest, `{"error":"invalid filter"}`},
+		{"/typed", http.StatusConflict, `{"error":"already exists"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if w.Code != tt.status {
+				t.Errorf("Expected status code %d, got %d", tt.status, w.Code)
+			}
+			if strings.TrimSpace(w.Body.String()) != tt.body {
+				t.Errorf("Expected body %s, got %s", tt.body, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+// Package vibetest provides helpers for testing routes served by a Vibe
+// router or any other http.Handler.
+//
+// Example:
+//
+//	func TestGetUser(t *testing.T) {
+//	    router := newRouter()
+//
+//	    resp := vibetest.Do(router, http.MethodGet, "/users/1", nil)
+//	    resp.AssertStatus(t, http.StatusOK)
+//	    resp.AssertJSON(t, map[string]string{"id": "1"})
+//	}
+package vibetest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// Response wraps the recorded response of a request made with Do.
+type Response struct {
+	*httptest.ResponseRecorder
+}
+
+// Do serves a request with the given method, path and body on handler and
+// returns the recorded response.
+//
+// The body may be nil, a string, a []byte or an io.Reader, which are sent
+// as-is; any other value is encoded as JSON and the Content-Type header is
+// set to "application/json".
+func Do(handler http.Handler, method, path string, body interface{}) *Response {
+	req := NewRequest(method, path, body)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return &Response{ResponseRecorder: w}
+}
+
+// NewRequest builds a test request with a body encoded as described in Do.
+// It can be used to add headers or cookies before serving the request.
+func NewRequest(method, path string, body interface{}) *http.Request {
+	var reader io.Reader
+	isJSON := false
+
+	switch b := body.(type) {
+	case nil:
+	case string:
+		reader = bytes.NewBufferString(b)
+	case []byte:
+		reader = bytes.NewBuffer(b)
+	case io.Reader:
+		reader = b
+	default:
+		data, err := json.Marshal(b)
+		if err != nil {
+			panic(fmt.Sprintf("vibetest: failed to encode request body: %v", err))
+		}
+		reader = bytes.NewBuffer(data)
+		isJSON = true
+	}
+
+	req := httptest.NewRequest(method, path, reader)
+	if isJSON {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req
+}
+
+// AssertStatus reports an error if the response status code is not status.
+func (r *Response) AssertStatus(t testing.TB, status int) {
+	t.Helper()
+
+	if r.Code != status {
+		t.Errorf("Expected status code %d, got %d (body: %s)", status, r.Code, r.Body.String())
+	}
+}
+
+// AssertJSON reports an error if the response body is not JSON equal to
+// expected. Both sides are compared after a JSON round trip, so expected
+// may be any value that encodes to the same JSON, such as a struct or map.
+func (r *Response) AssertJSON(t testing.TB, expected interface{}) {
+	t.Helper()
+
+	var got interface{}
+	if err := json.Unmarshal(r.Body.Bytes(), &got); err != nil {
+		t.Errorf("Failed to decode response body as JSON: %v (body: %s)", err, r.Body.String())
+		return
+	}
+
+	data, err := json.Marshal(expected)
+	if err != nil {
+		t.Errorf("Failed to encode expected value as JSON: %v", err)
+		return
+	}
+
+	var want interface{}
+	if err := json.Unmarshal(data, &want); err != nil {
+		t.Errorf("Failed to decode expected value as JSON: %v", err)
+		return
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected JSON body %s, got %s", data, bytes.TrimSpace(r.Body.Bytes()))
+	}
+}
+
+// DecodeInto decodes the JSON response body into v.
+func (r *Response) DecodeInto(v interface{}) error {
+	if err := json.Unmarshal(r.Body.Bytes(), v); err != nil {
+		return fmt.Errorf("failed to decode response body: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package vibetest_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/vibe-go/vibe"
+	"github.com/vibe-go/vibe/httpx"
+	"github.com/vibe-go/vibe/vibetest"
+)
+
+// recordingT captures assertion failures instead of failing the test.
+type recordingT struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recordingT) Helper() {}
+
+func (r *recordingT) Errorf(format string, args ...interface{}) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+type echo struct {
+	Name string `json:"name"`
+}
+
+func newRouter() *vibe.Router {
+	router := vibe.New()
+	router.Post("/echo", func(w http.ResponseWriter, r *http.Request) error {
+		var in echo
+		if err := httpx.DecodeJSON(r, &in); err != nil {
+			return httpx.BadRequest(w, err)
+		}
+		return httpx.JSON(w, in, http.StatusCreated)
+	})
+	return router
+}
+
+func TestDo(t *testing.T) {
+	router := newRouter()
+
+	resp := vibetest.Do(router, http.MethodPost, "/echo", echo{Name: "vibe"})
+	resp.AssertStatus(t, http.StatusCreated)
+	resp.AssertJSON(t, map[string]string{"name": "vibe"})
+
+	var out echo
+	if err := resp.DecodeInto(&out); err != nil {
+		t.Fatalf("DecodeInto returned error: %v", err)
+	}
+	if out.Name != "vibe" {
+		t.Errorf("Expected name 'vibe', got '%s'", out.Name)
+	}
+}
+
+func TestDoRawBody(t *testing.T) {
+	router := newRouter()
+
+	resp := vibetest.Do(router, http.MethodPost, "/echo", `{"name":"raw"}`)
+	resp.AssertStatus(t, http.StatusCreated)
+	resp.AssertJSON(t, echo{Name: "raw"})
+}
+
+func TestAssertionsFail(t *testing.T) {
+	router := newRouter()
+	resp := vibetest.Do(router, http.MethodPost, "/echo", "not json")
+
+	t.Run("AssertStatus", func(t *testing.T) {
+		rt := &recordingT{TB: t}
+		resp.AssertStatus(rt, http.StatusCreated)
+
+		if len(rt.failures) != 1 {
+			t.Errorf("Expected 1 failure, got %d", len(rt.failures))
+		}
+	})
+
+	t.Run("AssertJSON", func(t *testing.T) {
+		rt := &recordingT{TB: t}
+		resp.AssertJSON(rt, map[string]string{"error": "something else"})
+
+		if len(rt.failures) != 1 {
+			t.Errorf("Expected 1 failure, got %d", len(rt.failures))
+		}
+	})
+
+	t.Run("DecodeInto", func(t *testing.T) {
+		notJSON := vibetest.Do(http.NotFoundHandler(), http.MethodGet, "/", nil)
+
+		var v map[string]string
+		if err := notJSON.DecodeInto(&v); err == nil {
+			t.Error("Expected DecodeInto to fail for a non-JSON body")
+		}
+	})
+}